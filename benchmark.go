@@ -0,0 +1,266 @@
+// benchmark.go
+// Headless batch simulations for measuring AI performance.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/sirupsen/logrus"
+)
+
+// GameResult summarizes a finished headless game.
+type GameResult struct {
+	Winner  string // Empty if the turn limit was reached.
+	Turns   int
+	Correct bool
+
+	// WinningDeduction is the rule that completed the winner's solution.
+	WinningDeduction string
+
+	// TurnLimitReached is set when the game was abandoned without an accusation.
+	TurnLimitReached bool
+	// Stalemate is set when it was abandoned because nobody was learning
+	// anything; see WithStalemateDetection.
+	Stalemate bool
+}
+
+// Play runs the game to completion without printing anything.
+func (g *Game) Play(maxTurns int) GameResult {
+	for g.turn < maxTurns {
+		out := g.PlayTurn()
+		if g.Finished() {
+			return GameResult{Winner: g.Winner(), Turns: turnsPlayed(g, out), Correct: out.Correct, WinningDeduction: out.WinningDeduction}
+		}
+		if g.Stalemate() {
+			return GameResult{Turns: g.turn, Stalemate: true}
+		}
+	}
+	return GameResult{Turns: g.turn, TurnLimitReached: true}
+}
+
+// turnsPlayed counts the turns of a game that out just finished. A correct
+// accusation leaves the turn counter on the winning turn, while a win by
+// elimination has already moved it past the turn that knocked the last
+// rival out.
+func turnsPlayed(g *Game, out TurnOutcome) int {
+	if out.Correct {
+		return g.turn + 1
+	}
+	return g.turn
+}
+
+// RunSilent plays a dealt game to its end or turn limit without printing.
+func (g *Game) RunSilent() GameResult { return g.Play(g.TurnLimit()) }
+
+// RunBatch deals and silently plays one all-AI game per seed, in parallel.
+// Each game's seating, deal and AI choices draw only from its own seeded Rng.
+// Results are in seed order. If a game cannot be set up or dealt, the batch
+// stops and the first such error is returned.
+func RunBatch(cfg GameConfig, numAI int, seeds []int64) ([]GameResult, error) {
+	return runBatch(cfg, numAI, seeds, defaultTurnLimit, nil, nil)
+}
+
+// newBatchGame seats and deals an all-AI game from seed alone. Each brain
+// gets its own Rng, derived from the game's, so nothing a batch's other
+// workers draw can change how the game plays out.
+func newBatchGame(cfg GameConfig, numAI int, seed int64) (*Game, error) {
+	rng := rand.New(rand.NewSource(seed))
+	g, err := NewGameWithRng(cfg, 0, numAI, rng)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range g.Players {
+		p.(*AdvancedAIBrain).SetRng(rand.New(rand.NewSource(rng.Int63())))
+	}
+	return g, g.Deal()
+}
+
+// batchSeeds derives n game seeds from a base seed, so a whole batch can be
+// replayed with -seed.
+func batchSeeds(base int64, n int) []int64 {
+	rng := rand.New(rand.NewSource(base))
+	seeds := make([]int64, n)
+	for i := range seeds {
+		seeds[i] = rng.Int63()
+	}
+	return seeds
+}
+
+// runBatch is RunBatch with the benchmark commands' extras. Games stop
+// after turnLimit turns. If stats is not nil, it is subscribed to every
+// game. If onProgress is not nil, it is called once per finished game with
+// the number completed so far.
+func runBatch(cfg GameConfig, numAI int, seeds []int64, turnLimit int, stats *StatsCollector, onProgress func(done, total int)) ([]GameResult, error) {
+	numGames := len(seeds)
+	results := make([]GameResult, numGames)
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	var firstErr batchError
+	// Progress is counted under a lock so the callback sees done rise one at
+	// a time and its last call is always (numGames, numGames).
+	var progressMu sync.Mutex
+	done := 0
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= numGames || firstErr.Failed() {
+					return
+				}
+				g, err := newBatchGame(cfg, numAI, seeds[i])
+				if err != nil {
+					firstErr.Set(fmt.Errorf("seed %d: %w", seeds[i], err))
+					return
+				}
+				g.WithTurnLimit(turnLimit)
+				if stats != nil {
+					g.Subscribe(stats)
+				}
+				results[i] = g.Play(g.TurnLimit())
+				if stats != nil {
+					stats.GameOver(g)
+				}
+				progressMu.Lock()
+				done++
+				if onProgress != nil {
+					onProgress(done, numGames)
+				}
+				progressMu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return results, firstErr.Err()
+}
+
+// batchError keeps the first error reported by a batch's workers.
+type batchError struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (b *batchError) Set(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+func (b *batchError) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+func (b *batchError) Failed() bool { return b.Err() != nil }
+
+// progressBar reports batch progress on w. On a terminal it redraws a single
+// bar; otherwise it prints a plain line every 10%.
+type progressBar struct {
+	w       io.Writer
+	tty     bool
+	start   time.Time
+	mu      sync.Mutex
+	lastPct int
+}
+
+func newProgressBar(f *os.File) *progressBar {
+	info, err := f.Stat()
+	tty := err == nil && info.Mode()&os.ModeCharDevice != 0
+	return &progressBar{w: f, tty: tty, start: time.Now(), lastPct: -1}
+}
+
+func (p *progressBar) Update(done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pct := done * 100 / total
+	if pct == p.lastPct || (!p.tty && pct%10 != 0 && done != total) {
+		return
+	}
+	p.lastPct = pct
+
+	elapsed := time.Since(p.start)
+	eta := time.Duration(float64(elapsed) / float64(done) * float64(total-done)).Round(time.Second)
+	if !p.tty {
+		fmt.Fprintf(p.w, "%d/%d games (%d%%), ETA %s\n", done, total, pct, eta)
+		return
+	}
+	const width = 30
+	filled := pct * width / 100
+	fmt.Fprintf(p.w, "\r[%s%s] %3d%% %d/%d ETA %s ", strings.Repeat("█", filled), strings.Repeat("░", width-filled), pct, done, total, eta)
+	if done == total {
+		fmt.Fprintln(p.w)
+	}
+}
+
+func runBenchmark(numAI, numGames, turnLimit int, seed int64) {
+	if config.CheckPlayers(numAI) != nil || numGames < 1 {
+		C.Warn.Printf("Usage: benchmark <num_ai %d-%d> <num_games>\n", minPlayers, config.MaxPlayers())
+		return
+	}
+	// The brains narrate every deduction at info level; keep the batch quiet.
+	if log.GetLevel() > logrus.WarnLevel {
+		log.SetLevel(logrus.WarnLevel)
+	}
+
+	C.Header.Printf("--- Benchmarking %d games with %d AIs ---\n", numGames, numAI)
+	results, err := runBatch(config, numAI, batchSeeds(seed, numGames), turnLimit, nil, newProgressBar(os.Stderr).Update)
+	if err != nil {
+		C.Warn.Printf("Benchmark aborted: %v\n", err)
+		return
+	}
+
+	var solved, correct, stalled, totalTurns int
+	byRule := make(map[string]int)
+	for _, r := range results {
+		if r.TurnLimitReached {
+			stalled++
+			continue
+		}
+		solved++
+		if r.WinningDeduction != "" {
+			byRule[r.WinningDeduction]++
+		}
+		totalTurns += r.Turns
+		if r.Correct {
+			correct++
+		}
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendRows([]table.Row{
+		{"Games", numGames},
+		{"Accusations", solved},
+		{"Correct accusations", correct},
+		{"Turn limit reached", stalled},
+	})
+	if solved > 0 {
+		t.AppendRow(table.Row{"Average turns to accuse", fmt.Sprintf("%.1f", float64(totalTurns)/float64(solved))})
+	}
+	var rules []string
+	for rule := range byRule {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		t.AppendRow(table.Row{"Final piece by " + rule, byRule[rule]})
+	}
+	t.SetStyle(tableStyle(table.StyleLight))
+	t.Render()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRunBatchReportsEveryGame(t *testing.T) {
+	const games = 6
+	var calls [][2]int
+	results, err := runBatch(config, 3, batchSeeds(1, games), defaultTurnLimit, nil, func(done, total int) {
+		calls = append(calls, [2]int{done, total})
+	})
+	if err != nil {
+		t.Fatalf("runBatch: %v", err)
+	}
+	if len(results) != games {
+		t.Fatalf("%d results, want %d", len(results), games)
+	}
+	if len(calls) != games {
+		t.Fatalf("onProgress called %d times, want %d: %v", len(calls), games, calls)
+	}
+	for i, call := range calls {
+		if call != [2]int{i + 1, games} {
+			t.Errorf("call %d was %v, want (%d, %d)", i, call, i+1, games)
+		}
+	}
+}
+
+func TestBatchSeedReplaysTheBatch(t *testing.T) {
+	first, err := runBatch(config, 4, batchSeeds(42, 12), defaultTurnLimit, nil, nil)
+	if err != nil {
+		t.Fatalf("runBatch: %v", err)
+	}
+	second, err := runBatch(config, 4, batchSeeds(42, 12), defaultTurnLimit, nil, nil)
+	if err != nil {
+		t.Fatalf("runBatch: %v", err)
+	}
+	if !slices.Equal(first, second) {
+		t.Errorf("the same base seed gave different batches:\n%v\n%v", first, second)
+	}
+	if slices.Equal(batchSeeds(42, 12), batchSeeds(43, 12)) {
+		t.Error("different base seeds gave the same game seeds")
+	}
+}
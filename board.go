@@ -0,0 +1,59 @@
+// board.go
+// Board mode: every player's token is in a room, and a suggestion must name
+// the room the suggester is in, as in the board game.
+
+package main
+
+// roomCategory is the category a board-mode suggestion's room comes from.
+const roomCategory = "rooms"
+
+// RoomAware players are told where their token is before each suggestion.
+// Players that are not still have the room filled in for them.
+type RoomAware interface {
+	EnterRoom(room string)
+}
+
+// WithBoardMode turns on board mode. Tokens are placed in random rooms by
+// Deal, and each turn the player moves to a different random room before
+// suggesting. There is no board geometry: any other room is a legal move.
+func (g *Game) WithBoardMode() *Game {
+	g.boardPosition = make(map[string]string)
+	return g
+}
+
+// BoardMode reports whether suggestions are tied to the suggester's room.
+func (g *Game) BoardMode() bool { return g.boardPosition != nil }
+
+// Room returns the room player's token is in, or "" outside board mode.
+func (g *Game) Room(player string) string { return g.boardPosition[player] }
+
+// placeTokens puts every player's token in a random room.
+func (g *Game) placeTokens() {
+	for _, p := range g.Players {
+		g.boardPosition[p.Name()] = g.Config.Rooms[g.rng.Intn(len(g.Config.Rooms))]
+	}
+}
+
+// moveToken moves p's token to a random other room, tells p where it is now
+// and returns the room.
+func (g *Game) moveToken(p Player) string {
+	room := g.boardPosition[p.Name()]
+	if len(g.Config.Rooms) > 1 {
+		next := g.rng.Intn(len(g.Config.Rooms) - 1)
+		if g.Config.Rooms[next] == room {
+			next = len(g.Config.Rooms) - 1
+		}
+		room = g.Config.Rooms[next]
+	}
+	g.boardPosition[p.Name()] = room
+	if ra, ok := p.(RoomAware); ok {
+		ra.EnterRoom(room)
+	}
+	return room
+}
+
+// EnterRoom ties the brain's next suggestions to room.
+func (ai *AdvancedAIBrain) EnterRoom(room string) { ai.room = room }
+
+// EnterRoom ties the human's next suggestions to room.
+func (h *HumanPlayer) EnterRoom(room string) { h.room = room }
@@ -0,0 +1,169 @@
+// challenge.go
+// Shareable "solve this in N turns" puzzles cut from exported scenarios.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"maps"
+	"os"
+	"strings"
+)
+
+// Challenge is a puzzle built from a scenario: the solver sits in Seat, sees
+// the log up to FromTurn, and tries to name the solution in fewer further
+// turns than Par, the number a perfect deducer in that seat would need.
+type Challenge struct {
+	Scenario Scenario `json:"scenario"` // The full game; only the solver's view is shown.
+	Seat     string   `json:"seat"`
+	FromTurn int      `json:"from_turn"`
+	Par      int      `json:"par"`
+}
+
+// ExportChallenge cuts a challenge from a scenario, starting after fromTurn
+// logged turns and played from the given seat. It fails if a perfect player
+// in that seat could never pin the solution down from the log.
+func ExportChallenge(sc *Scenario, cfg GameConfig, seat string, fromTurn int) (Challenge, error) {
+	if fromTurn < 0 || fromTurn > len(sc.Log) {
+		return Challenge{}, fmt.Errorf("starting turn must be between 0 and %d", len(sc.Log))
+	}
+	if _, ok := sc.Hands[seat]; !ok {
+		return Challenge{}, fmt.Errorf("unknown seat %q", seat)
+	}
+	solvedAt := EarliestSolveTurn(sc, cfg, seat)
+	if solvedAt < 0 {
+		return Challenge{}, fmt.Errorf("the log never reveals the solution to %s", seat)
+	}
+	return Challenge{Scenario: *sc, Seat: seat, FromTurn: fromTurn, Par: max(0, solvedAt-fromTurn)}, nil
+}
+
+// Write saves the challenge as indented JSON.
+func (ch *Challenge) Write(w io.Writer) error {
+	data, err := json.MarshalIndent(ch, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// LoadChallenge reads a challenge file and checks it against the config.
+func LoadChallenge(path string, cfg GameConfig) (*Challenge, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ch Challenge
+	if err := json.Unmarshal(data, &ch); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := ch.Scenario.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", path, ErrScenarioInvalid, err)
+	}
+	if _, ok := ch.Scenario.Hands[ch.Seat]; !ok || ch.FromTurn < 0 || ch.FromTurn > len(ch.Scenario.Log) {
+		return nil, fmt.Errorf("%s: %w: bad seat or starting turn", path, ErrScenarioInvalid)
+	}
+	return &ch, nil
+}
+
+// view returns turn i of the log as the seat saw it: the shown card is only
+// kept if the seat suggested or disproved.
+func (ch *Challenge) view(i int) TurnRecord {
+	t := ch.Scenario.Log[i]
+	if t.Suggester != ch.Seat && t.Disprover != ch.Seat {
+		t.Shown = ""
+	}
+	return t
+}
+
+func runExportChallenge(scenarioPath string, seat, fromTurn int, outPath string) error {
+	sc, err := LoadScenario(scenarioPath, config)
+	if err != nil {
+		return err
+	}
+	if seat < 1 || seat > len(sc.Players) {
+		return fmt.Errorf("seat must be between 1 and %d", len(sc.Players))
+	}
+	ch, err := ExportChallenge(sc, config, sc.Players[seat-1], fromTurn)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := ch.Write(f); err != nil {
+		return err
+	}
+	C.Info.Printf("Wrote a challenge for %s from turn %d to %s. Par: %d more turns.\n", ch.Seat, ch.FromTurn, outPath, ch.Par)
+	return nil
+}
+
+// runPlayChallenge shows the solver the game so far from their seat and
+// reveals one more turn at a time until they accuse.
+func runPlayChallenge(line InputSource, path string) {
+	ch, err := LoadChallenge(path, config)
+	if err != nil {
+		C.Warn.Printf("Could not load challenge: %v\n", err)
+		return
+	}
+	sc := ch.Scenario
+	C.Header.Println("\n--- Cluedo Challenge ---")
+	C.Info.Printf("Players: %s\n", strings.Join(sc.Players, ", "))
+	C.Info.Printf("You are %s. Your hand: %s\n", colorizeCard(ch.Seat), strings.Join(sc.Hands[ch.Seat], ", "))
+	C.Info.Printf("A perfect detective names the solution after %d more turn(s). Can you?\n", ch.Par)
+	C.Header.Println("\n--- The game so far ---")
+	for i := 0; i < ch.FromTurn; i++ {
+		fmt.Printf("%2d: %s\n", i+1, ch.view(i))
+	}
+
+	revealed := ch.FromTurn
+	for {
+		input, err := line.Prompt("(challenge: next, accuse, quit) ")
+		if err != nil {
+			C.Info.Println("Goodbye!")
+			return
+		}
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "next", "n":
+			if revealed == len(sc.Log) {
+				C.Warn.Println("That was the last logged turn. Time to accuse!")
+				continue
+			}
+			fmt.Printf("%2d: %s\n", revealed+1, ch.view(revealed))
+			revealed++
+		case "accuse", "a":
+			C.Info.Printf("Name one card from each of: %s.\n", strings.Join(config.CategoryNames(), ", "))
+			cards, err := promptForCards(line, true, len(config.Categories))
+			if err != nil {
+				C.Info.Println("Goodbye!")
+				return
+			}
+			guess := make(map[string]string)
+			for _, card := range cards {
+				guess[config.CardToType[card]] = card
+			}
+			used := revealed - ch.FromTurn
+			if !isCompleteSuggestion(config, guess) || !maps.Equal(guess, sc.Solution) {
+				C.No.Printf("Wrong! The solution was %v.\n", values(sc.Solution))
+				return
+			}
+			switch {
+			case used < ch.Par:
+				C.Yes.Printf("Correct after %d turn(s), beating par (%d)! Was that a lucky guess?\n", used, ch.Par)
+			case used == ch.Par:
+				C.Yes.Printf("Correct after %d turn(s): a perfect score.\n", used)
+			default:
+				C.Yes.Printf("Correct after %d turn(s). Par was %d.\n", used, ch.Par)
+			}
+			return
+		case "quit", "q":
+			C.Info.Printf("The solution was %v.\n", values(sc.Solution))
+			return
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// chooser.go
+// Ways for a brain to pick one card from a list of candidates.
+
+package main
+
+// Chooser picks one of a non-empty list of cards.
+type Chooser interface {
+	Choose(cards []string) string
+}
+
+// RandomChooser picks uniformly at random.
+type RandomChooser struct{ Rng Rng }
+
+func (c RandomChooser) Choose(cards []string) string { return cards[c.Rng.Intn(len(cards))] }
+
+// WeightedChooser picks in proportion to Score, or the highest-scoring card
+// (the first of any tie) when Argmax is set. Negative scores count as zero;
+// if every score is zero it picks uniformly.
+type WeightedChooser struct {
+	Rng    Rng
+	Score  func(card string) float64
+	Argmax bool
+}
+
+// weightResolution is how finely a proportional draw divides the total weight.
+const weightResolution = 1 << 30
+
+func (c WeightedChooser) Choose(cards []string) string {
+	weights := make([]float64, len(cards))
+	total := 0.0
+	best := 0
+	for i, card := range cards {
+		weights[i] = max(0, c.Score(card))
+		total += weights[i]
+		if weights[i] > weights[best] {
+			best = i
+		}
+	}
+	if total == 0 {
+		return RandomChooser{c.Rng}.Choose(cards)
+	}
+	if c.Argmax {
+		return cards[best]
+	}
+	r := float64(c.Rng.Intn(weightResolution)) / weightResolution * total
+	for i, w := range weights {
+		if r < w {
+			return cards[i]
+		}
+		r -= w
+	}
+	return cards[best] // Only reached through rounding.
+}
@@ -5,15 +5,24 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"io/ioutil"
+	"maps"
+	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"text/tabwriter"
 	"time"
 
 	"github.com/fatih/color"
@@ -21,34 +30,146 @@ import (
 	"github.com/jedib0t/go-pretty/v6/text"
 	"github.com/peterh/liner"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
 )
 
 // --- Global Variables and Types ---
 
 var log = logrus.New()
-var C = struct {
+
+// quietly runs f with the brains' info-level narration muted, for work whose
+// deductions are not news: what-if trials, replays and advisors.
+func quietly(f func()) {
+	if level := log.GetLevel(); level > logrus.WarnLevel {
+		log.SetLevel(logrus.WarnLevel)
+		defer log.SetLevel(level)
+	}
+	f()
+}
+
+// Theme holds the colors used to render output. Each detective session owns
+// its own theme, so sessions with custom colors never affect each other.
+type Theme struct {
 	Yes, No, Maybe, Info, Warn, Header, Prompt, Debug *color.Color
-}{
-	Yes:    color.New(color.FgGreen),
-	No:     color.New(color.FgRed),
-	Maybe:  color.New(color.FgYellow),
-	Info:   color.New(color.FgCyan),
-	Warn:   color.New(color.FgHiYellow),
-	Header: color.New(color.FgWhite, color.Bold),
-	Prompt: color.New(color.FgHiWhite),
-	Debug:  color.New(color.FgMagenta),
+	SuspectColors                                     map[string]*color.Color
 }
 
+// NewTheme returns the default color theme.
+func NewTheme() *Theme {
+	return &Theme{
+		Yes:    color.New(color.FgGreen),
+		No:     color.New(color.FgRed),
+		Maybe:  color.New(color.FgYellow),
+		Info:   color.New(color.FgCyan),
+		Warn:   color.New(color.FgHiYellow),
+		Header: color.New(color.FgWhite, color.Bold),
+		Prompt: color.New(color.FgHiWhite),
+		Debug:  color.New(color.FgMagenta),
+		SuspectColors: map[string]*color.Color{
+			"Miss Scarlett":   color.New(color.FgRed),
+			"Colonel Mustard": color.New(color.FgYellow),
+			"Mrs. White":      color.New(color.FgWhite),
+			"Mr. Green":       color.New(color.FgGreen),
+			"Mrs. Peacock":    color.New(color.FgBlue),
+			"Professor Plum":  color.New(color.FgMagenta),
+		},
+	}
+}
+
+// C is the default theme, used for output that doesn't belong to a session.
+var C = NewTheme()
+
 type GameConfig struct {
-	Suspects   []string `json:"suspects"`
-	Weapons    []string `json:"weapons"`
-	Rooms      []string `json:"rooms"`
-	AllCards   []string
-	CardToType map[string]string
+	Suspects   []string          `json:"suspects" yaml:"suspects"`
+	Weapons    []string          `json:"weapons" yaml:"weapons"`
+	Rooms      []string          `json:"rooms" yaml:"rooms"`
+	AllCards   []string          `yaml:"-"`
+	CardToType map[string]string `yaml:"-"`
+
+	// Extra lists categories beyond suspects, weapons and rooms, as in some
+	// editions. The solution holds one card of every category.
+	Extra []Category `json:"extra_categories,omitempty" yaml:"extra_categories,omitempty"`
+
+	// Categories is every category in order, suspects first. It is filled in
+	// by buildIndex.
+	Categories []Category `json:"-" yaml:"-"`
+
+	// AI is how the AI players of games on this config play. It comes from
+	// the command line, never from the file.
+	AI AIOptions `json:"-" yaml:"-"`
+}
+
+// Category is one kind of card, such as the weapons.
+type Category struct {
+	Name  string   `json:"name" yaml:"name"`
+	Cards []string `json:"cards" yaml:"cards"`
+}
+
+// CategoryNames returns the names of every category, in order.
+func (cfg GameConfig) CategoryNames() []string {
+	var names []string
+	for _, cat := range cfg.Categories {
+		names = append(names, cat.Name)
+	}
+	return names
 }
 
 var config GameConfig
 
+// showProbabilities adds estimated percentages to unknown notes cells.
+var showProbabilities bool
+
+// asciiOnly replaces block and box-drawing characters with plain ASCII.
+var asciiOnly bool
+
+// tableStyle is style, or plain ASCII borders under asciiOnly.
+func tableStyle(style table.Style) table.Style {
+	if asciiOnly {
+		return table.StyleDefault
+	}
+	return style
+}
+
+// revealPrivateCards lets a simulation's narration name the card each
+// suggester was shown in private. It is for the spectator only: the players
+// are still told exactly what they would see at the table.
+var revealPrivateCards bool
+
+// CardsOf returns the card list for a category, such as "weapons".
+func (cfg GameConfig) CardsOf(category string) []string {
+	for _, cat := range cfg.Categories {
+		if cat.Name == category {
+			return cat.Cards
+		}
+	}
+	return nil
+}
+
+// minPlayers is the smallest table a game can be dealt to; the largest has
+// one player per suspect.
+const minPlayers = 2
+
+// Errors returned by CheckPlayers. Callers can match them with errors.Is.
+var (
+	ErrTooFewPlayers  = errors.New("too few players")
+	ErrTooManyPlayers = errors.New("too many players")
+)
+
+// MaxPlayers is the largest table the config can seat: one per suspect.
+func (cfg GameConfig) MaxPlayers() int { return len(cfg.Suspects) }
+
+// CheckPlayers reports whether a game of n players can be set up with this
+// config. NewGame expects a count that passes it.
+func (cfg GameConfig) CheckPlayers(n int) error {
+	if n < minPlayers {
+		return fmt.Errorf("%w: %d, need at least %d", ErrTooFewPlayers, n, minPlayers)
+	}
+	if n > cfg.MaxPlayers() {
+		return fmt.Errorf("%w: %d, this config has only %d suspects", ErrTooManyPlayers, n, cfg.MaxPlayers())
+	}
+	return nil
+}
+
 // --- Player Interface ---
 
 type Player interface {
@@ -59,66 +180,310 @@ type Player interface {
 	MakeSuggestion() map[string]string
 	ShouldAccuse() map[string]string
 	ProcessTurnInfo(suggester, disprover, revealedCard string, suggestion map[string]string)
-	ChooseCardToShow(suggestion map[string]string) string
+	ChooseCardToShow(suggester string, suggestion map[string]string) string
 	DisplayNotes()
 }
 
-var SuspectColors = map[string]*color.Color{
-	"Miss Scarlett":   color.New(color.FgRed),
-	"Colonel Mustard": color.New(color.FgYellow),
-	"Mrs. White":      color.New(color.FgWhite),
-	"Mr. Green":       color.New(color.FgGreen),
-	"Mrs. Peacock":    color.New(color.FgBlue),
-	"Professor Plum":  color.New(color.FgMagenta),
-}
-
 // Helper to get a color for a card name, defaulting to white.
-func colorizeCard(name string) string {
-	if c, ok := SuspectColors[name]; ok {
+func colorizeCard(name string) string { return C.Card(name) }
+
+func makeAiTitle(name string) string { return C.AiTitle(name) }
+
+// Card colors a card name with its suspect color, if it has one. Weapons
+// and rooms are left plain.
+func (t *Theme) Card(name string) string {
+	if c := t.playerColor(name); c != nil {
 		return c.Sprint(name)
 	}
 	return name // Default color
 }
 
-func makeAiTitle(name string) string {
-	if c, ok := SuspectColors[name]; ok {
+// AiTitle renders the "[name's Brain]" label used in AI log lines.
+func (t *Theme) AiTitle(name string) string {
+	if c := t.playerColor(name); c != nil {
 		return c.Sprintf("[%s's Brain]", name)
 	}
 	return name // Default color
 }
 
+// hashedPlayerColors is the palette for suspects and players missing from
+// SuspectColors, e.g. in large custom editions or detective-mode tables.
+var hashedPlayerColors = []*color.Color{
+	color.New(color.FgHiRed),
+	color.New(color.FgHiGreen),
+	color.New(color.FgHiYellow),
+	color.New(color.FgHiBlue),
+	color.New(color.FgHiMagenta),
+	color.New(color.FgHiCyan),
+	color.New(color.FgCyan),
+}
+
+// playerColor returns name's color from SuspectColors or, for any other
+// suspect or player name, a palette color picked by hashing the name, so the
+// same name always looks the same. It returns nil for weapons and rooms.
+func (t *Theme) playerColor(name string) *color.Color {
+	if c, ok := t.SuspectColors[name]; ok {
+		return c
+	}
+	if cat, isCard := config.CardToType[name]; name == "" || isCard && cat != "suspects" {
+		return nil
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return hashedPlayerColors[h.Sum32()%uint32(len(hashedPlayerColors))]
+}
+
 // --- Main Game Struct ---
 
+// defaultTurnLimit ends a simulation that nobody has solved.
+const defaultTurnLimit = 50
+
 type Game struct {
-	Config   GameConfig
-	Players  []Player
-	Solution map[string]string
-	turn     int
+	Config    GameConfig
+	Players   []Player
+	Solution  map[string]string
+	Hands     map[string][]string // Ground truth, filled in by Deal.
+	OpenHands bool                // Teaching mode: every shown card is announced to all players.
+	turn      int
+	rng       Rng
+	turnLimit int // 0 means defaultTurnLimit.
+
+	// Players knocked out by a wrong accusation. They no longer take turns
+	// but, as in the board game, still show cards to disprove suggestions.
+	eliminated map[string]bool
+	seat       int    // Index of the player whose turn it is.
+	winner     string // Set once the game is decided.
+
+	history []TurnOutcome // Every turn played since the deal, oldest first.
+
+	// turnDelay is how long the narrated simulation pauses after each AI
+	// turn, so a reader can keep up. Zero, the default, means no pause.
+	turnDelay time.Duration
+
+	// boardPosition maps each player to the room their token is in. It is
+	// nil unless board mode is on; see WithBoardMode.
+	boardPosition map[string]string
+
+	// stalemateRounds ends an all-AI game once no player has learned
+	// anything for this many full rounds; 0, the default, never does. See
+	// WithStalemateDetection.
+	stalemateRounds    int
+	turnsSinceProgress int
+
+	// teams maps players to their partnership, if any; see SetTeams.
+	teams map[string]int
+
+	// err is why the game was abandoned, e.g. a human's ErrUserQuit; see Err.
+	err error
+
+	// listeners are told about each turn as it is played. They may be
+	// (un)subscribed from other goroutines, e.g. an HTTP handler, while
+	// the game plays, so they are guarded by listenersMu.
+	listenersMu sync.RWMutex
+	listeners   []TurnListener
+}
+
+// WithTurnLimit sets how many turns a simulation may run before it is
+// abandoned as a stalemate. Values below 1 restore the default.
+func (g *Game) WithTurnLimit(n int) *Game {
+	g.turnLimit = max(0, n)
+	return g
+}
+
+// WithStalemateDetection gives up on the game once a full round of turns
+// has gone by rounds times without any AI player filling in a notes cell.
+// Zero turns detection off. Games with a human seated are never given up:
+// only AI notes can be watched, and the human may still be learning.
+func (g *Game) WithStalemateDetection(rounds int) *Game {
+	g.stalemateRounds = max(0, rounds)
+	return g
+}
+
+// Stalemate reports whether the game was given up because nobody was
+// learning anything; see WithStalemateDetection.
+func (g *Game) Stalemate() bool {
+	if g.stalemateRounds == 0 || g.winner != "" || slices.ContainsFunc(g.Players, Player.IsHuman) {
+		return false
+	}
+	active := len(g.Players) - len(g.eliminated)
+	return g.turnsSinceProgress >= g.stalemateRounds*active
+}
+
+// trackProgress counts the turns since an AI player last learned something.
+// Accusations count as progress: a wrong one changes who is at the table.
+// Without AI players there is no telling, so the count never grows.
+func (g *Game) trackProgress(out TurnOutcome) {
+	if out.Accusation != nil {
+		g.turnsSinceProgress = 0
+		return
+	}
+	judged := false
+	for _, p := range g.Players {
+		if ai, ok := p.(*AdvancedAIBrain); ok {
+			judged = true
+			// A turn without a suggestion told nobody anything.
+			if out.Suggestion != nil && ai.LastTurnGain() > 0 {
+				g.turnsSinceProgress = 0
+				return
+			}
+		}
+	}
+	if judged {
+		g.turnsSinceProgress++
+	}
 }
 
-func NewGame(cfg GameConfig, numHumans, numAI int) *Game {
-	playerNames := cfg.Suspects[:numHumans+numAI]
-	rand.Shuffle(len(playerNames), func(i, j int) { playerNames[i], playerNames[j] = playerNames[j], playerNames[i] })
+// WithTurnDelay makes the narrated simulation pause for d after every AI
+// turn. Headless play never pauses.
+func (g *Game) WithTurnDelay(d time.Duration) *Game {
+	g.turnDelay = max(0, d)
+	return g
+}
+
+// TurnLimit returns the number of turns a simulation may run.
+func (g *Game) TurnLimit() int {
+	if g.turnLimit == 0 {
+		return defaultTurnLimit
+	}
+	return g.turnLimit
+}
+
+// Rng is the source of randomness used by games and brains. *rand.Rand
+// satisfies it, so seeded or scripted sources can be injected.
+type Rng interface {
+	Intn(n int) int
+	Shuffle(n int, swap func(i, j int))
+}
+
+// globalRng is the default Rng, backed by the math/rand global source.
+type globalRng struct{}
+
+func (globalRng) Intn(n int) int                     { return rand.Intn(n) }
+func (globalRng) Shuffle(n int, swap func(i, j int)) { rand.Shuffle(n, swap) }
+
+// lockedRng is a seeded Rng that, like the global source, is safe to share
+// between the games a benchmark plays in parallel.
+type lockedRng struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func newLockedRng(seed int64) *lockedRng { return &lockedRng{r: rand.New(rand.NewSource(seed))} }
+
+func (l *lockedRng) Intn(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Intn(n)
+}
+
+func (l *lockedRng) Shuffle(n int, swap func(i, j int)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.r.Shuffle(n, swap)
+}
 
-	g := &Game{Config: cfg, Solution: make(map[string]string)}
+// defaultRng is what new games and brains draw from unless given their own.
+var defaultRng Rng = globalRng{}
+
+func NewGame(cfg GameConfig, numHumans, numAI int) (*Game, error) {
+	return NewGameWithRng(cfg, numHumans, numAI, defaultRng)
+}
+
+// NewGameWithRng creates a game whose seating, deal and AI brains all draw
+// from the given source of randomness. It returns an error wrapping
+// ErrTooFewPlayers or ErrTooManyPlayers if the config cannot seat that many.
+func NewGameWithRng(cfg GameConfig, numHumans, numAI int, rng Rng) (*Game, error) {
+	if numHumans < 0 || numAI < 0 {
+		return nil, fmt.Errorf("player counts cannot be negative")
+	}
+	if err := cfg.CheckPlayers(numHumans + numAI); err != nil {
+		return nil, err
+	}
+	// Copy the names so shuffling never reorders the shared config.
+	playerNames := append([]string{}, cfg.Suspects[:numHumans+numAI]...)
+	rng.Shuffle(len(playerNames), func(i, j int) { playerNames[i], playerNames[j] = playerNames[j], playerNames[i] })
+
+	g := &Game{Config: cfg, Solution: make(map[string]string), rng: rng, eliminated: make(map[string]bool)}
 
 	for i, name := range playerNames {
 		var p Player
 		if i < numHumans {
-			p = NewHumanPlayer()
+			p = NewHumanPlayer(nil)
 		} else {
-			p = NewAdvancedAIBrain()
+			ai := cfg.AI.NewBrain()
+			ai.SetRng(rng)
+			p = ai
 		}
 		p.Setup(cfg, playerNames, name)
 		g.Players = append(g.Players, p)
 	}
+	return g, nil
+}
+
+// WithBrains replaces every AI player with one built by newBrain for its
+// seat, counted from 0, so a game can mix AI personalities. Names and the
+// game's Rng carry over. Call it before dealing.
+func (g *Game) WithBrains(newBrain func(seat int) *AdvancedAIBrain) *Game {
+	names := make([]string, len(g.Players))
+	for i, p := range g.Players {
+		names[i] = p.Name()
+	}
+	for i, p := range g.Players {
+		if p.IsHuman() {
+			continue
+		}
+		ai := newBrain(i)
+		ai.SetRng(g.rng)
+		ai.Setup(g.Config, names, names[i])
+		g.Players[i] = ai
+	}
+	return g
+}
+
+// SetSeating pins who plays and in what order: names[0] takes the first
+// turn. Every name must be a suspect of the config, once, and the list must
+// cover every seat. Humans keep the first seats. Call it before dealing.
+func (g *Game) SetSeating(names []string) error {
+	if g.Hands != nil {
+		return fmt.Errorf("the cards have already been dealt")
+	}
+	if len(names) != len(g.Players) {
+		return fmt.Errorf("%d names given for %d seats", len(names), len(g.Players))
+	}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if !slices.Contains(g.Config.Suspects, name) {
+			return fmt.Errorf("%q is not a suspect in this config", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("%q is seated twice", name)
+		}
+		seen[name] = true
+	}
+	for i, p := range g.Players {
+		p.Setup(g.Config, names, names[i])
+	}
+	return nil
+}
+
+// WithAccusationThreshold lets every AI player accuse once its best guess is
+// at least this likely; see AccusationPolicy.
+func (g *Game) WithAccusationThreshold(threshold float64) *Game {
+	for _, p := range g.Players {
+		if ai, ok := p.(*AdvancedAIBrain); ok {
+			ai.WithAccusationThreshold(threshold)
+		}
+	}
 	return g
 }
 
-func (g *Game) Deal() {
+// Deal shuffles the deck, draws the solution and deals the rest round the
+// table. It returns an error, leaving the game unfit to play, if the cards
+// dealt do not add up to the config's deck exactly.
+func (g *Game) Deal() error {
 	deck := make([]string, len(g.Config.AllCards))
 	copy(deck, g.Config.AllCards)
-	rand.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+	g.rng.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
 
 	dealtCategories := make(map[string]bool)
 	var cardsToDeal []string
@@ -140,14 +505,315 @@ func (g *Game) Deal() {
 		hands[playerIndex] = append(hands[playerIndex], card)
 	}
 
+	g.Hands = make(map[string][]string)
+	for i, p := range g.Players {
+		g.Hands[p.Name()] = hands[i]
+	}
+	if err := g.checkDeal(); err != nil {
+		return err
+	}
 	for i, p := range g.Players {
 		p.ReceiveHand(hands[i])
 		log.Debugf("%s Hand: %v", p.Name(), hands[i])
 	}
+	if g.BoardMode() {
+		g.placeTokens()
+	}
 	log.Debugf("Ground Truth Initialized. Solution: %+v", g.Solution)
+	return nil
+}
+
+// checkDeal verifies that the hands and the solution together hold every
+// card of the deck exactly once.
+func (g *Game) checkDeal() error {
+	dealt := make(map[string]int)
+	for _, card := range g.Solution {
+		dealt[card]++
+	}
+	for _, hand := range g.Hands {
+		for _, card := range hand {
+			dealt[card]++
+		}
+	}
+	var errs []error
+	inDeck := make(map[string]bool)
+	for _, card := range g.Config.AllCards {
+		if inDeck[card] {
+			errs = append(errs, fmt.Errorf("card %q is in the deck twice", card))
+			continue
+		}
+		inDeck[card] = true
+		if n := dealt[card]; n != 1 {
+			errs = append(errs, fmt.Errorf("card %q dealt %d times", card, n))
+		}
+		delete(dealt, card)
+	}
+	for _, card := range slices.Sorted(maps.Keys(dealt)) {
+		errs = append(errs, fmt.Errorf("card %q is not in the deck", card))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("corrupt deal: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Redeal starts the game over with a fresh deal: same players, seating and
+// config, but a new solution and new hands. Every player is set up again, so
+// no brain carries knowledge over from the previous deal.
+func (g *Game) Redeal() error {
+	names := make([]string, len(g.Players))
+	for i, p := range g.Players {
+		names[i] = p.Name()
+	}
+	for _, p := range g.Players {
+		p.Setup(g.Config, names, p.Name())
+	}
+	g.Solution = make(map[string]string)
+	g.turn, g.seat, g.winner = 0, 0, ""
+	g.turnsSinceProgress = 0
+	g.eliminated = make(map[string]bool)
+	g.history = nil
+	return g.Deal()
+}
+
+// TurnOutcome describes what happened during a single turn.
+type TurnOutcome struct {
+	Player       Player
+	Accusation   map[string]string // Non-nil if the player accused instead of suggesting.
+	Correct      bool
+	Suggestion   map[string]string // Nil if the player made no valid suggestion.
+	Strategy     string            // The AI strategy behind the suggestion, if any.
+	Disprover    string
+	RevealedCard string
+
+	// WinningDeduction is the rule that completed an accusing AI's solution.
+	WinningDeduction string
+
+	// Eliminated is set when a wrong accusation knocked the player out.
+	Eliminated bool
+
+	// Room is the room the player suggested from, in board mode.
+	Room string
+
+	// Passed lists the players, in order, who could not disprove the suggestion.
+	Passed []string
+}
+
+// TurnListener is told about turns as they are played or replayed.
+// JSONStreamRenderer is one.
+type TurnListener interface {
+	Turn(turn int, out TurnOutcome)
+}
+
+// TurnListenerFunc adapts a plain function to a TurnListener.
+type TurnListenerFunc func(turn int, out TurnOutcome)
+
+func (f TurnListenerFunc) Turn(turn int, out TurnOutcome) { f(turn, out) }
+
+// History returns every turn played since the deal, oldest first.
+func (g *Game) History() []TurnOutcome {
+	return append([]TurnOutcome{}, g.history...)
+}
+
+// Replay hands a recorded history to a listener, numbering turns from 1, so
+// an old game can be narrated again or fed to a different observer.
+func Replay(history []TurnOutcome, l TurnListener) {
+	for i, out := range history {
+		l.Turn(i+1, out)
+	}
+}
+
+// CurrentPlayer returns the player whose turn it is.
+func (g *Game) CurrentPlayer() Player { return g.Players[g.seat] }
+
+// Winner returns the player who won, or "" while the game is undecided.
+func (g *Game) Winner() string { return g.winner }
+
+// Finished reports whether someone accused correctly or everyone else was
+// eliminated.
+func (g *Game) Finished() bool { return g.winner != "" }
+
+// Err returns why the game was abandoned, such as ErrUserQuit when a human
+// player left, or nil.
+func (g *Game) Err() error { return g.err }
+
+// IsEliminated reports whether a wrong accusation knocked the player out.
+func (g *Game) IsEliminated(name string) bool { return g.eliminated[name] }
+
+// advance ends the current turn and passes play to the next player still in
+// the game.
+func (g *Game) advance() {
+	g.turn++
+	for i := 1; i <= len(g.Players); i++ {
+		next := (g.seat + i) % len(g.Players)
+		if !g.eliminated[g.Players[next].Name()] {
+			g.seat = next
+			return
+		}
+	}
+}
+
+// PlayTurn plays the current player's turn without printing anything and
+// adds it to the history. The turn counter advances unless the player
+// accused correctly. A wrong accusation eliminates the player; if only one
+// player is left, they win. If a human player leaves instead, the game is
+// abandoned: Err reports why, and the turn is neither recorded nor reported.
+func (g *Game) PlayTurn() TurnOutcome {
+	out := g.playTurn()
+	if g.err != nil {
+		return out
+	}
+	g.trackProgress(out)
+	g.history = append(g.history, out)
+	// Dispatch over a snapshot, unlocked, so listeners may (un)subscribe.
+	g.listenersMu.RLock()
+	listeners := slices.Clone(g.listeners)
+	g.listenersMu.RUnlock()
+	for _, l := range listeners {
+		l.Turn(len(g.history), out)
+	}
+	return out
+}
+
+// Subscribe has l told about every turn played from now on, numbered from 1
+// since the deal. Subscribing a listener twice has no effect.
+func (g *Game) Subscribe(l TurnListener) {
+	g.listenersMu.Lock()
+	defer g.listenersMu.Unlock()
+	for _, existing := range g.listeners {
+		if sameListener(existing, l) {
+			return
+		}
+	}
+	g.listeners = append(g.listeners, l)
+}
+
+// Unsubscribe stops telling l about turns and reports whether it was
+// subscribed. Listeners are matched by identity, so use a pointer such as a
+// *JSONStreamRenderer; a TurnListenerFunc cannot be compared and so cannot
+// be unsubscribed.
+func (g *Game) Unsubscribe(l TurnListener) bool {
+	g.listenersMu.Lock()
+	defer g.listenersMu.Unlock()
+	for i, existing := range g.listeners {
+		if sameListener(existing, l) {
+			g.listeners = slices.Delete(g.listeners, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// sameListener compares listeners without panicking on uncomparable ones.
+func sameListener(a, b TurnListener) bool {
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
+
+func (g *Game) playTurn() TurnOutcome {
+	currentPlayer := g.CurrentPlayer()
+	out := TurnOutcome{Player: currentPlayer}
+
+	accusation := currentPlayer.ShouldAccuse()
+	if g.err = playerErr(currentPlayer); g.err != nil {
+		return out
+	}
+	if accusation != nil {
+		out.Accusation = accusation
+		out.Correct = g.checkAccusation(accusation)
+		if ai, ok := currentPlayer.(*AdvancedAIBrain); ok {
+			out.WinningDeduction = ai.WinningDeduction()
+		}
+		if out.Correct {
+			g.winner = currentPlayer.Name()
+			return out
+		}
+		out.Eliminated = true
+		g.eliminated[currentPlayer.Name()] = true
+		g.advance()
+		if g.oneSideLeft() {
+			g.winner = g.CurrentPlayer().Name()
+		}
+		return out
+	}
+
+	if g.BoardMode() {
+		out.Room = g.moveToken(currentPlayer)
+	}
+	suggestion := currentPlayer.MakeSuggestion()
+	if g.err = playerErr(currentPlayer); g.err != nil {
+		return out
+	}
+	if out.Room != "" && suggestion != nil && suggestion[roomCategory] != out.Room {
+		log.Debugf("%s is in the %s, so their suggestion names it instead of %s.", currentPlayer.Name(), out.Room, suggestion[roomCategory])
+		suggestion[roomCategory] = out.Room
+	}
+	if !isCompleteSuggestion(g.Config, suggestion) {
+		// A partial suggestion would be read as "nobody disproved", so skip the turn instead.
+		g.advance()
+		return out
+	}
+	out.Suggestion = suggestion
+	if ai, ok := currentPlayer.(*AdvancedAIBrain); ok {
+		out.Strategy = ai.lastStrategy
+	}
+	out.Disprover, out.RevealedCard = g.HandleSuggestion(currentPlayer, suggestion)
+	for i := 1; i < len(g.Players); i++ {
+		p := g.Players[(g.seat+i)%len(g.Players)].Name()
+		if p == out.Disprover {
+			break
+		}
+		out.Passed = append(out.Passed, p)
+	}
+
+	if out.RevealedCard != "" {
+		if ai, ok := g.Players[g.playerIndex(out.Disprover)].(*AdvancedAIBrain); ok {
+			for _, mate := range g.Teammates(currentPlayer.Name()) {
+				// Best effort: the disprover may be one of the team.
+				_ = ai.RecordDisclosure(mate, out.RevealedCard)
+			}
+		}
+	}
+	for _, p := range g.Players {
+		// Only the suggester and their teammates see the shown card, unless
+		// hands are open.
+		revealed := ""
+		if g.OpenHands || p.Name() == currentPlayer.Name() || g.sameTeam(p.Name(), currentPlayer.Name()) {
+			revealed = out.RevealedCard
+		}
+		p.ProcessTurnInfo(currentPlayer.Name(), out.Disprover, revealed, suggestion)
+	}
+	g.advance()
+	return out
+}
+
+// playerErr returns why p left the game, if p is a human who did.
+func playerErr(p Player) error {
+	if h, ok := p.(*HumanPlayer); ok {
+		return h.Err()
+	}
+	return nil
+}
+
+func (g *Game) checkAccusation(accusation map[string]string) bool {
+	for cat, card := range accusation {
+		if g.Solution[cat] != card {
+			return false
+		}
+	}
+	return true
 }
 
+// HandleSuggestion asks the other players in turn order to disprove a
+// suggestion and returns the first to show a card, and the card. Eliminated
+// players still show cards, as in the board game: every brain takes a pass
+// to mean the player holds none of the cards, and skipping them would break
+// that deduction.
 func (g *Game) HandleSuggestion(suggester Player, suggestion map[string]string) (string, string) {
+	if !isCompleteSuggestion(g.Config, suggestion) {
+		log.Warnf("Ignoring malformed suggestion from %s: %v", suggester.Name(), suggestion)
+		return "", ""
+	}
 	suggesterIdx := -1
 	for i, p := range g.Players {
 		if p.Name() == suggester.Name() {
@@ -155,12 +821,16 @@ func (g *Game) HandleSuggestion(suggester Player, suggestion map[string]string)
 			break
 		}
 	}
+	if suggesterIdx == -1 {
+		log.Warnf("Ignoring suggestion from unknown player %s.", suggester.Name())
+		return "", ""
+	}
 
 	for i := 1; i < len(g.Players); i++ {
 		playerIdx := (suggesterIdx + i) % len(g.Players)
 		playerToAsk := g.Players[playerIdx]
 
-		cardShown := playerToAsk.ChooseCardToShow(suggestion)
+		cardShown := playerToAsk.ChooseCardToShow(suggester.Name(), suggestion)
 		if cardShown != "" {
 			return playerToAsk.Name(), cardShown
 		}
@@ -177,6 +847,35 @@ type AdvancedAIBrain struct {
 	knowledge             map[string]map[string]CardStatus
 	unresolvedSuggestions []UnresolvedSuggestion
 	recentSurgicalTargets *StringDeque
+	rng                   Rng
+	strategyStats         map[string]*banditArm
+	lastStrategy          string // Strategy behind our pending suggestion.
+	turnsSeen             int    // Suggestions observed since the deal.
+	lastGain              int    // Notes cells filled in by the last ProcessTurnInfo.
+	solvedBy              string // Rule that placed the last solution card.
+	handSizes             map[string]int
+	strategies            []string     // Strategies this brain may use, in priority order; nil means DefaultStrategies.
+	history               []TurnRecord // Everything passed to ProcessTurnInfo since the deal.
+	progress              []int        // Solved categories after each history entry.
+
+	disclosed    map[string]map[string]bool // Cards we have shown, by the player we showed them to.
+	showStrategy ShowStrategy               // How we pick a card to show; nil means DefaultShowStrategy.
+	accusation   AccusationPolicy           // When to accuse without being certain.
+	patience     int                        // How many recent Surgical Strike targets to avoid.
+	exploitMRV   bool                       // Whether Exploit probes the category closest to solved; see WithExploitMRV.
+	room         string                     // In board mode, the room we must suggest.
+	reasoning    []Deduction                // Every placement, in the order it was made.
+
+	// OnMysteryCreated, if set, is called whenever a disproval leaves us
+	// knowing only that the disprover holds one of cards.
+	OnMysteryCreated func(disprover string, cards []string)
+	// OnMysterySolved, if set, is called whenever a mystery collapses to one card.
+	OnMysterySolved func(disprover, card string)
+	// OnSolutionFound, if set, is called once every category is solved.
+	OnSolutionFound func(solution map[string]string)
+
+	solutionAnnounced bool            // OnSolutionFound has fired for these notes.
+	warnedAbout       map[string]bool // Contradicted cards ShouldAccuse has already warned about.
 }
 
 type CardStatus string
@@ -187,22 +886,57 @@ const (
 	StatusMaybe CardStatus = "Maybe"
 )
 
+// UnmarshalJSON accepts a status in any letter case, so hand-edited files
+// load, and rejects anything that is not a status. Statuses are already
+// written as their names, so no MarshalJSON is needed.
+func (s *CardStatus) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("card status must be a string: %w", err)
+	}
+	for _, status := range []CardStatus{StatusYes, StatusNo, StatusMaybe} {
+		if strings.EqualFold(name, string(status)) {
+			*s = status
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid card status %q (want Yes, No or Maybe)", name)
+}
+
 type UnresolvedSuggestion struct {
 	Disprover     string
 	PossibleCards map[string]struct{}
 }
 
-func NewAdvancedAIBrain() *AdvancedAIBrain { return &AdvancedAIBrain{} }
-func (ai *AdvancedAIBrain) Name() string   { return ai.name }
-func (ai *AdvancedAIBrain) IsHuman() bool  { return false }
+func NewAdvancedAIBrain() *AdvancedAIBrain {
+	return &AdvancedAIBrain{rng: defaultRng, patience: DefaultPatience}
+}
+func (ai *AdvancedAIBrain) Name() string  { return ai.name }
+func (ai *AdvancedAIBrain) IsHuman() bool { return false }
+
+// SetRng replaces the brain's source of randomness.
+func (ai *AdvancedAIBrain) SetRng(r Rng) { ai.rng = r }
 
 func (ai *AdvancedAIBrain) Setup(cfg GameConfig, playerNames []string, myName string) {
 	ai.name = myName
 	ai.config = cfg
-	ai.players = playerNames
+	ai.players = append([]string{}, playerNames...)
 	ai.hand = make(map[string]struct{})
 	ai.unresolvedSuggestions = []UnresolvedSuggestion{}
-	ai.recentSurgicalTargets = NewStringDeque(3)
+	ai.recentSurgicalTargets = NewStringDeque(ai.patience)
+	ai.room = ""
+	ai.strategyStats = make(map[string]*banditArm)
+	ai.lastStrategy = ""
+	ai.turnsSeen = 0
+	ai.lastGain = 0
+	ai.solvedBy = ""
+	ai.history = nil
+	ai.progress = nil
+	ai.disclosed = make(map[string]map[string]bool)
+	ai.reasoning = nil
+	ai.solutionAnnounced = false
+	ai.warnedAbout = make(map[string]bool)
+	ai.handSizes = dealtHandSizes(len(cfg.AllCards)-len(cfg.Categories), ai.players)
 	ai.knowledge = make(map[string]map[string]CardStatus)
 	for _, card := range ai.config.AllCards {
 		ai.knowledge[card] = make(map[string]CardStatus)
@@ -214,54 +948,442 @@ func (ai *AdvancedAIBrain) Setup(cfg GameConfig, playerNames []string, myName st
 	log.Debugf("[%s's Brain] Master deduction engine initialized.", ai.name)
 }
 
+// Clone returns an independent copy of the brain for what-if analysis. Notes,
+// hand, mysteries and strategy statistics are deep-copied; the config and Rng
+// are shared, and the OnMysteryCreated, OnMysterySolved and OnSolutionFound
+// hooks are not carried over.
+func (ai *AdvancedAIBrain) Clone() *AdvancedAIBrain {
+	c := &AdvancedAIBrain{
+		name:         ai.name,
+		config:       ai.config,
+		players:      append([]string{}, ai.players...),
+		hand:         make(map[string]struct{}),
+		knowledge:    ai.deepCopyKnowledge(),
+		rng:          ai.rng,
+		lastStrategy: ai.lastStrategy,
+		turnsSeen:    ai.turnsSeen,
+		lastGain:     ai.lastGain,
+		solvedBy:     ai.solvedBy,
+		handSizes:    make(map[string]int),
+		strategies:   ai.strategies,
+		history:      append([]TurnRecord{}, ai.history...),
+		progress:     append([]int{}, ai.progress...),
+		disclosed:    make(map[string]map[string]bool),
+		showStrategy: ai.showStrategy,
+		accusation:   ai.accusation,
+		patience:     ai.patience,
+		exploitMRV:   ai.exploitMRV,
+		room:         ai.room,
+		reasoning:    slices.Clone(ai.reasoning),
+
+		solutionAnnounced: ai.solutionAnnounced,
+		warnedAbout:       maps.Clone(ai.warnedAbout),
+	}
+	for p, cards := range ai.disclosed {
+		c.disclosed[p] = make(map[string]bool)
+		for card := range cards {
+			c.disclosed[p][card] = true
+		}
+	}
+	for p, n := range ai.handSizes {
+		c.handSizes[p] = n
+	}
+	for card := range ai.hand {
+		c.hand[card] = struct{}{}
+	}
+	for _, mystery := range ai.unresolvedSuggestions {
+		copied := UnresolvedSuggestion{Disprover: mystery.Disprover, PossibleCards: make(map[string]struct{})}
+		for card := range mystery.PossibleCards {
+			copied.PossibleCards[card] = struct{}{}
+		}
+		c.unresolvedSuggestions = append(c.unresolvedSuggestions, copied)
+	}
+	if ai.recentSurgicalTargets != nil {
+		c.recentSurgicalTargets = &StringDeque{elements: append([]string{}, ai.recentSurgicalTargets.elements...), maxSize: ai.recentSurgicalTargets.maxSize}
+	}
+	if ai.strategyStats != nil {
+		c.strategyStats = make(map[string]*banditArm)
+		for name, arm := range ai.strategyStats {
+			copied := *arm
+			c.strategyStats[name] = &copied
+		}
+	}
+	return c
+}
+
+// dealtHandSizes splits numCards as Game.Deal does: round-robin in seating
+// order, so when they don't divide evenly the first players get one extra.
+func dealtHandSizes(numCards int, players []string) map[string]int {
+	sizes := make(map[string]int)
+	for i, p := range players {
+		sizes[p] = numCards / len(players)
+		if i < numCards%len(players) {
+			sizes[p]++
+		}
+	}
+	return sizes
+}
+
+// SetHandSizes overrides the hand sizes assumed in Setup, for tables that
+// were not dealt in seating order. Players not listed keep their size.
+func (ai *AdvancedAIBrain) SetHandSizes(sizes map[string]int) {
+	for p, n := range sizes {
+		ai.handSizes[p] = n
+	}
+	ai._runDeductionLoop()
+}
+
 func (ai *AdvancedAIBrain) ReceiveHand(cards []string) {
+	// Our own hand size is a fact, whatever the seating suggested.
+	ai.handSizes[ai.name] = len(cards)
 	// THE FIX: Process the hand to update the initial knowledge grid.
 	for _, card := range cards {
 		ai.hand[card] = struct{}{}
 		// Use our central method to record this certain fact.
-		ai._markCardLocation(card, ai.name)
+		ai._markCardLocation(card, ai.name, RuleOwnHand)
 	}
 	// After processing the entire hand, run the deduction engine to see
 	// if any simple eliminations can be made immediately.
 	ai._runDeductionLoop()
 }
 
+// Rewind forgets the last n turns and reveals. The notes are rebuilt from
+// scratch: the brain is set up again, dealt its hand and replayed everything
+// but those n entries. Hand sizes are kept.
+func (ai *AdvancedAIBrain) Rewind(n int) error {
+	if n < 0 || n > len(ai.history) {
+		return fmt.Errorf("can only rewind between 0 and %d entries", len(ai.history))
+	}
+	// The replayed deductions are not news; keep them out of the log.
+	quietly(func() { ai._rebuild(ai.history[:len(ai.history)-n]) })
+	return nil
+}
+
+// DeleteEntry forgets the i-th history entry, counted from 0, and rebuilds
+// the notes from the rest. Mysteries are pruned in the order turns arrive,
+// so nothing short of a full replay gives the right notes.
+func (ai *AdvancedAIBrain) DeleteEntry(i int) error {
+	if i < 0 || i >= len(ai.history) {
+		return fmt.Errorf("there is no entry %d", i+1)
+	}
+	quietly(func() { ai._rebuild(slices.Delete(slices.Clone(ai.history), i, i+1)) })
+	return nil
+}
+
+// ReplayEntries applies logged entries in order without narrating the
+// deductions, e.g. to put back the entries after one that was re-entered.
+func (ai *AdvancedAIBrain) ReplayEntries(entries []TurnRecord) {
+	quietly(func() {
+		for _, t := range entries {
+			ai.ProcessTurnInfo(t.Suggester, t.Disprover, t.Shown, t.Suggestion)
+		}
+	})
+}
+
+// _rebuild sets the brain up again, deals it its hand and replays history.
+// Hand sizes and disclosures are kept; the hooks stay quiet.
+func (ai *AdvancedAIBrain) _rebuild(history []TurnRecord) {
+	hand := ai.Hand()
+	sizes := ai.handSizes
+	disclosed := ai.disclosed
+	createdHook, mysteryHook, solutionHook := ai.OnMysteryCreated, ai.OnMysterySolved, ai.OnSolutionFound
+	ai.OnMysteryCreated, ai.OnMysterySolved, ai.OnSolutionFound = nil, nil, nil
+	defer func() {
+		ai.OnMysteryCreated, ai.OnMysterySolved, ai.OnSolutionFound = createdHook, mysteryHook, solutionHook
+	}()
+
+	ai.Setup(ai.config, ai.players, ai.name)
+	ai.handSizes = sizes
+	ai.disclosed = disclosed
+	ai.ReceiveHand(hand)
+	for _, t := range history {
+		ai.ProcessTurnInfo(t.Suggester, t.Disprover, t.Shown, t.Suggestion)
+	}
+}
+
+// LastTurnGain returns how many notes cells the last ProcessTurnInfo call
+// filled in, counting everything deduced from it.
+func (ai *AdvancedAIBrain) LastTurnGain() int { return ai.lastGain }
+
+// Hand returns the brain's own cards in sorted order.
+func (ai *AdvancedAIBrain) Hand() []string {
+	return sortedKeys(ai.hand)
+}
+
 func (ai *AdvancedAIBrain) ProcessTurnInfo(suggester, disprover, revealedCard string, suggestion map[string]string) {
+	knownBefore := ai._knownCellCount()
+	defer func() { ai.lastGain = ai._knownCellCount() - knownBefore }()
 	if suggester == "Game Event" {
 		// This is a direct reveal, a certain fact.
 		// The 'disprover' field is used to carry the player name.
 		if disprover != "" && revealedCard != "" {
-			ai._markCardLocation(revealedCard, disprover)
+			ai.history = append(ai.history, TurnRecord{Suggester: suggester, Disprover: disprover, Shown: revealedCard})
+			ai._markCardLocation(revealedCard, disprover, RuleRevealed)
 			ai._runDeductionLoop()
+			ai.progress = append(ai.progress, len(ai._knownSolutionCards()))
 		}
 		return // Stop processing here.
 	}
+	if !isCompleteSuggestion(ai.config, suggestion) {
+		// A missing or unknown card would end up in the notes as a bogus fact.
+		log.Warnf("[%s's Brain] ignoring malformed suggestion %v from %s.", ai.name, suggestion, suggester)
+		return
+	}
+	ai.history = append(ai.history, TurnRecord{Suggester: suggester, Suggestion: suggestion, Disprover: disprover, Shown: revealedCard})
 
+	ai.turnsSeen++
 	if ai.name == suggester {
 		if disprover != "" && revealedCard != "" {
-			ai._markCardLocation(revealedCard, disprover)
+			if ai.knowledge[revealedCard][disprover] == StatusYes {
+				// A repeat showing teaches nothing: a player may show any
+				// matching card, so it says nothing about the others named.
+				log.Debugf("[%s's Brain] %s showed %s again; nothing new.", ai.name, disprover, revealedCard)
+			}
+			ai._markCardLocation(revealedCard, disprover, RuleRevealed)
 		} else if disprover == "" {
 			log.Infof("[%s] My suggestion was not disproved! Making powerful deductions.", colorizeCard(ai.name))
 			for _, card := range suggestion {
 				if _, inHand := ai.hand[card]; !inHand {
-					ai._markCardLocation(card, "solution")
+					ai._markCardLocation(card, "solution", RuleUndisproved)
 				}
 			}
+		} else {
+			// Our own suggestion was disproved but we don't know which card we
+			// were shown (e.g. it wasn't logged). It is still a mystery, and
+			// several of them from the same player narrow down their hand.
+			ai._recordMystery(disprover, suggestion)
 		}
 	} else if disprover != "" && disprover != ai.name {
-		newMystery := UnresolvedSuggestion{Disprover: disprover, PossibleCards: make(map[string]struct{})}
+		if revealedCard != "" {
+			// Open hands: the shown card was announced to everyone.
+			ai._markCardLocation(revealedCard, disprover, RuleRevealed)
+		} else {
+			ai._recordMystery(disprover, suggestion)
+		}
+	} else if disprover == "" {
+		// Nobody could disprove: each card is in the solution or in the
+		// suggester's own hand (they may be bluffing). The passes below rule
+		// out everyone else.
+		log.Infof("%s noted that nobody disproved %s; those cards are theirs or the solution's.", makeAiTitle(ai.name), colorizeCard(suggester))
+	}
+	// Everyone asked before the disprover passed, so holds none of the cards.
+	// This covers every later turn too, so no history of who showed what for
+	// which suggestion is needed to learn that a past disprover lacks a card.
+	for _, p := range ai._passedPlayers(suggester, disprover) {
 		for _, card := range suggestion {
-			newMystery.PossibleCards[card] = struct{}{}
+			if ai.knowledge[card][p] == StatusMaybe {
+				ai.knowledge[card][p] = StatusNo
+			}
 		}
-		ai.unresolvedSuggestions = append(ai.unresolvedSuggestions, newMystery)
-
-		// log.Infof("[%s's Brain] noted that %s holds one of %v. (New unsolved mystery)", colorizeCard(ai.name), disprover, mapKeys(newMystery.PossibleCards))
-		log.Infof("%s noted that %s holds one of %v. (New unsolved mystery)", makeAiTitle(ai.name), disprover, mapKeys(newMystery.PossibleCards))
 	}
 	ai._runDeductionLoop()
+	if ai.name == suggester {
+		ai._rewardStrategy(ai._knownCellCount() - knownBefore)
+	}
+	ai.progress = append(ai.progress, len(ai._knownSolutionCards()))
+}
+
+// _passedPlayers returns the players asked, in seating order, before the
+// disprover answered the suggester; everyone else if nobody did.
+func (ai *AdvancedAIBrain) _passedPlayers(suggester, disprover string) []string {
+	start := slices.Index(ai.players, suggester)
+	if start < 0 {
+		return nil
+	}
+	var passed []string
+	for i := 1; i < len(ai.players); i++ {
+		p := ai.players[(start+i)%len(ai.players)]
+		if p == disprover {
+			break
+		}
+		passed = append(passed, p)
+	}
+	return passed
+}
+
+// RecordDisclosure notes that we showed one of our cards to a player. It
+// teaches us nothing new, but tells us what that opponent now knows.
+func (ai *AdvancedAIBrain) RecordDisclosure(player, card string) error {
+	if _, ok := ai.hand[card]; !ok {
+		return fmt.Errorf("%s is not in your hand", card)
+	}
+	if player == ai.name || !ai.isPlayer(player) {
+		return fmt.Errorf("%s is not an opponent at this table", player)
+	}
+	if ai.disclosed[player] == nil {
+		ai.disclosed[player] = make(map[string]bool)
+	}
+	ai.disclosed[player][card] = true
+	return nil
+}
+
+// OpponentKnows estimates which cards a player knows are not the solution:
+// their own cards, as far as we know them, plus every card we showed them.
+func (ai *AdvancedAIBrain) OpponentKnows(player string) []string {
+	var known []string
+	for _, card := range ai.config.AllCards {
+		if ai.knowledge[card][player] == StatusYes || ai.disclosed[player][card] {
+			known = append(known, card)
+		}
+	}
+	return known
+}
+
+// ProgressHistory returns how many solution categories were solved after
+// each turn or reveal, oldest first.
+func (ai *AdvancedAIBrain) ProgressHistory() []int { return ai.progress }
+
+// KnowledgeSummary is a quick measure of how close a brain is to solving.
+type KnowledgeSummary struct {
+	Categories []CategorySummary
+	Mysteries  int // Unresolved suggestions still being tracked.
+}
+
+// CategorySummary counts what is still open in one category.
+type CategorySummary struct {
+	Name       string
+	Candidates int // Cards that may still be the solution; 1 once solved.
+	Unplaced   int // Cards whose location is not known yet.
+}
+
+// Solved reports whether every category is down to one candidate.
+func (s KnowledgeSummary) Solved() bool {
+	for _, cat := range s.Categories {
+		if cat.Candidates != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Summary counts the open questions in the notes.
+func (ai *AdvancedAIBrain) Summary() KnowledgeSummary {
+	summary := KnowledgeSummary{Mysteries: len(ai.unresolvedSuggestions)}
+	for _, cat := range ai.config.Categories {
+		cs := CategorySummary{Name: cat.Name}
+		for _, card := range cat.Cards {
+			if ai.knowledge[card]["solution"] != StatusNo {
+				cs.Candidates++
+			}
+			placed := false
+			for _, status := range ai.knowledge[card] {
+				if status == StatusYes {
+					placed = true
+					break
+				}
+			}
+			if !placed {
+				cs.Unplaced++
+			}
+		}
+		summary.Categories = append(summary.Categories, cs)
+	}
+	return summary
+}
+
+// ConflictSource finds the logged entries behind the belief that player
+// does not hold card. It returns how many of the most recent entries must be
+// undone for that belief to go away, or -1 if it follows from our own hand
+// alone and so cannot be blamed on a logging mistake.
+func (ai *AdvancedAIBrain) ConflictSource(player, card string) int {
+	for n := 1; n <= len(ai.history); n++ {
+		c := ai.Clone()
+		if err := c.Rewind(n); err != nil {
+			break
+		}
+		if c.knowledge[card][player] != StatusNo {
+			return n
+		}
+	}
+	return -1
 }
 
-func (ai *AdvancedAIBrain) ChooseCardToShow(suggestion map[string]string) string {
+// ValidateTurn checks a turn against what the brain already knows, without
+// applying it. It returns an error describing the first contradiction found,
+// such as a card shown by a player we know does not hold it.
+func (ai *AdvancedAIBrain) ValidateTurn(suggester, disprover, revealedCard string, suggestion map[string]string) error {
+	if !isCompleteSuggestion(ai.config, suggestion) {
+		return fmt.Errorf("the suggestion must name one card of each category")
+	}
+	start := -1
+	for i, p := range ai.players {
+		if p == suggester {
+			start = i
+		}
+	}
+	if start == -1 {
+		return fmt.Errorf("%s is not at the table", suggester)
+	}
+	cards := values(suggestion)
+	sort.Strings(cards)
+
+	// Everyone between the suggester and the disprover passed.
+	for i := 1; i < len(ai.players); i++ {
+		p := ai.players[(start+i)%len(ai.players)]
+		if p == disprover {
+			break
+		}
+		for _, card := range cards {
+			if ai.knowledge[card][p] == StatusYes {
+				return fmt.Errorf("%s passed, but we know they hold %s", p, card)
+			}
+		}
+	}
+	if disprover == "" {
+		return nil
+	}
+	if disprover == suggester {
+		return fmt.Errorf("%s cannot disprove their own suggestion", suggester)
+	}
+	canHold := false
+	for _, card := range cards {
+		if ai.knowledge[card][disprover] != StatusNo {
+			canHold = true
+		}
+	}
+	if !canHold {
+		return fmt.Errorf("%s disproved, but we know they hold none of %s", disprover, strings.Join(cards, ", "))
+	}
+	if revealedCard == "" {
+		return nil
+	}
+	if suggestion[ai.config.CardToType[revealedCard]] != revealedCard {
+		return fmt.Errorf("%s was not one of the suggested cards", revealedCard)
+	}
+	if ai.knowledge[revealedCard][disprover] == StatusNo {
+		for loc, status := range ai.knowledge[revealedCard] {
+			if status == StatusYes {
+				holder := loc
+				if loc == "solution" {
+					holder = "the solution"
+				}
+				return fmt.Errorf("%s showed %s, but it is already placed with %s", disprover, revealedCard, holder)
+			}
+		}
+		return fmt.Errorf("%s showed %s, but we know they do not hold it", disprover, revealedCard)
+	}
+	return nil
+}
+
+// _recordMystery notes that the disprover holds at least one of the suggested cards.
+func (ai *AdvancedAIBrain) _recordMystery(disprover string, suggestion map[string]string) {
+	newMystery := UnresolvedSuggestion{Disprover: disprover, PossibleCards: make(map[string]struct{})}
+	for _, card := range suggestion {
+		newMystery.PossibleCards[card] = struct{}{}
+	}
+	ai.unresolvedSuggestions = append(ai.unresolvedSuggestions, newMystery)
+
+	log.Infof("%s noted that %s holds one of %v. (New unsolved mystery)", makeAiTitle(ai.name), disprover, mapKeys(newMystery.PossibleCards))
+	if ai.OnMysteryCreated != nil {
+		var cards []string
+		for _, cat := range ai.config.CategoryNames() {
+			cards = append(cards, suggestion[cat])
+		}
+		ai.OnMysteryCreated(disprover, cards)
+	}
+}
+
+func (ai *AdvancedAIBrain) ChooseCardToShow(suggester string, suggestion map[string]string) string {
 	var canShow []string
 	for _, card := range suggestion {
 		if _, ok := ai.hand[card]; ok {
@@ -271,246 +1393,1116 @@ func (ai *AdvancedAIBrain) ChooseCardToShow(suggestion map[string]string) string
 	if len(canShow) == 0 {
 		return ""
 	}
-	return canShow[rand.Intn(len(canShow))]
+	sort.Strings(canShow) // Map order is random; keep the choice up to the strategy.
+	strategy := ai.showStrategy
+	if strategy == nil {
+		strategy = DefaultShowStrategy
+	}
+	card := strategy.ChooseCard(ai, suggester, canShow)
+	if err := ai.RecordDisclosure(suggester, card); err != nil {
+		log.Debugf("[%s's Brain] not tracking what %s was shown: %v", ai.name, suggester, err)
+	}
+	return card
 }
 
 func (ai *AdvancedAIBrain) MakeSuggestion() map[string]string {
+	suggestion := ai._legalSuggestion(ai._chooseSuggestion())
+	if ai.room != "" {
+		// The strategies pick freely; the board has the last word on the room.
+		suggestion[roomCategory] = ai.room
+	}
+	return suggestion
+}
+
+// _legalSuggestion enforces MakeSuggestion's post-condition: a legal
+// suggestion names exactly one card per category. A strategy's malformed
+// suggestion is replaced by an exploration one.
+func (ai *AdvancedAIBrain) _legalSuggestion(suggestion map[string]string) map[string]string {
+	if isCompleteSuggestion(ai.config, suggestion) {
+		return suggestion
+	}
+	log.Errorf("[%s's Brain] produced a malformed suggestion %v; falling back to exploration.", ai.name, suggestion)
+	return ai._buildExplorationSuggestion()
+}
+
+func (ai *AdvancedAIBrain) _chooseSuggestion() map[string]string {
 	log.Debugf("[%s's Brain] Formulating a master-level suggestion...", ai.name)
 
-	// Priority 1: Exploit
-	var knownSolutionCards = make(map[string]string)
-	knownCount := 0
-	for _, cat := range []string{"suspects", "weapons", "rooms"} {
-		cardList := ai.config.Suspects
-		if cat == "weapons" {
-			cardList = ai.config.Weapons
+	knownSolutionCards := ai._knownSolutionCards()
+	strategies := ai.strategies
+	if strategies == nil {
+		strategies = DefaultStrategies
+	}
+	applicable := []string{}
+	for _, name := range strategies {
+		switch {
+		case name == StrategyExploit && len(knownSolutionCards) >= 1,
+			name == StrategySurgical && len(ai.unresolvedSuggestions) > 0,
+			name == StrategyFocus && ai._focusCategory() != "",
+			name == StrategyBluff && len(ai.hand) > 0 && ai.rng.Intn(bluffOdds) == 0,
+			name == StrategyExplore, name == StrategyInfoGain:
+			applicable = append(applicable, name)
+		}
+	}
+	if len(applicable) == 0 {
+		applicable = append(applicable, StrategyExplore)
+	}
+
+	ai.lastStrategy = ai._selectStrategy(applicable)
+	switch ai.lastStrategy {
+	case StrategyExploit:
+		log.Infof("[%s] Strategy: EXPLOIT. I know %d/%d of the solution, testing a theory.", colorizeCard(ai.name), len(knownSolutionCards), len(ai.config.Categories))
+		return ai._buildExploitSuggestion(knownSolutionCards)
+	case StrategySurgical:
+		if suggestion, ok := ai._buildSurgicalStrike(); ok {
+			return suggestion
+		}
+		ai.lastStrategy = StrategyExplore
+	case StrategyFocus:
+		return ai._buildFocusSuggestion()
+	case StrategyBluff:
+		return ai._buildBluffSuggestion()
+	case StrategyInfoGain:
+		return ai._buildInfoGainSuggestion()
+	}
+	log.Infof("[%s] Strategy: EXPLORE. Gathering new information.", colorizeCard(ai.name))
+	return ai._buildExplorationSuggestion()
+}
+
+// _knownSolutionCards returns the solution card of every solved category.
+func (ai *AdvancedAIBrain) _knownSolutionCards() map[string]string {
+	known := make(map[string]string)
+	for _, cat := range ai.config.CategoryNames() {
+		for _, card := range ai.config.CardsOf(cat) {
+			if ai.knowledge[card]["solution"] == StatusYes {
+				known[cat] = card
+				break
+			}
+		}
+	}
+	return known
+}
+
+// _focusCategory returns the unsolved category with the fewest solution
+// candidates left, or "" if every category is solved.
+func (ai *AdvancedAIBrain) _focusCategory() string {
+	known := ai._knownSolutionCards()
+	best, fewest := "", 0
+	for _, cat := range ai.config.CategoryNames() {
+		if known[cat] != "" {
+			continue
+		}
+		if n := len(ai._solutionCandidates(cat)); n > 1 && (best == "" || n < fewest) {
+			best, fewest = cat, n
+		}
+	}
+	return best
+}
+
+// _solutionCandidates lists a category's cards that may still be the solution.
+func (ai *AdvancedAIBrain) _solutionCandidates(category string) []string {
+	var candidates []string
+	for _, card := range ai.config.CardsOf(category) {
+		if ai.knowledge[card]["solution"] == StatusMaybe {
+			candidates = append(candidates, card)
+		}
+	}
+	return candidates
+}
+
+// _buildFocusSuggestion probes one candidate of the category closest to being
+// solved. The other slots hold cards nobody else can show, known solution
+// cards or our own, so any card shown must be the probed one.
+func (ai *AdvancedAIBrain) _buildFocusSuggestion() map[string]string {
+	cat := ai._focusCategory()
+	candidates := ai._solutionCandidates(cat)
+	target := candidates[ai.rng.Intn(len(candidates))]
+	log.Infof("[%s] Strategy: FOCUS. %s are down to %d candidates; probing '%s'.", colorizeCard(ai.name), cat, len(candidates), target)
+	suggestion := ai._buildSuggestionAroundTarget(target)
+	for known, card := range ai._knownSolutionCards() {
+		if known != cat {
+			suggestion[known] = card
+		}
+	}
+	return suggestion
+}
+
+// _buildBluffSuggestion explores as usual but names one of our own cards in
+// place of an unknown one. Observers who see it disproved record a mystery
+// that includes a card nobody else can show, wasting their deductions.
+// Cards we have never shown are preferred, since nobody can rule them out.
+func (ai *AdvancedAIBrain) _buildBluffSuggestion() map[string]string {
+	suggestion := ai._buildExplorationSuggestion()
+	solved := ai._knownSolutionCards()
+	var secret, shown []string
+	for _, card := range ai.Hand() {
+		if _, ok := solved[ai.config.CardToType[card]]; ok {
+			continue // Naming the known answer is more useful than a bluff there.
+		}
+		if ai._everDisclosed(card) {
+			shown = append(shown, card)
+		} else {
+			secret = append(secret, card)
+		}
+	}
+	candidates := secret
+	if len(candidates) == 0 {
+		candidates = shown
+	}
+	if len(candidates) == 0 {
+		return suggestion
+	}
+	card := candidates[ai.rng.Intn(len(candidates))]
+	suggestion[ai.config.CardToType[card]] = card
+	log.Infof("[%s] Strategy: BLUFF. Naming my own '%s' to mislead the table.", colorizeCard(ai.name), card)
+	return suggestion
+}
+
+// _everDisclosed reports whether we have shown card to anyone.
+func (ai *AdvancedAIBrain) _everDisclosed(card string) bool {
+	for _, cards := range ai.disclosed {
+		if cards[card] {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultPatience is how many recent Surgical Strike targets new brains
+// avoid re-targeting. Larger tables may want more, to stop the brain
+// alternating between the same two targets.
+const DefaultPatience = 3
+
+// WithPatience sets how many recent Surgical Strike targets the brain avoids;
+// 0 lets it re-target the most frequent card every turn.
+func (ai *AdvancedAIBrain) WithPatience(n int) *AdvancedAIBrain {
+	ai.patience = n
+	if ai.recentSurgicalTargets != nil {
+		ai.recentSurgicalTargets = NewStringDeque(n)
+	}
+	return ai
+}
+
+// _buildSurgicalStrike targets one of the cards that appear in the most
+// unresolved mysteries, avoiding the last few targets (see WithPatience).
+// Among the top few, cards in more mysteries are drawn more often.
+func (ai *AdvancedAIBrain) _buildSurgicalStrike() (map[string]string, bool) {
+	cardFrequency := make(map[string]int)
+	for _, mystery := range ai.unresolvedSuggestions {
+		for card := range mystery.PossibleCards {
+			cardFrequency[card]++
+		}
+	}
+	if len(cardFrequency) == 0 {
+		return nil, false
+	}
+	var sortedTargets []string
+	for card := range cardFrequency {
+		sortedTargets = append(sortedTargets, card)
+	}
+	// Break frequency ties alphabetically, so the same notes always yield the
+	// same targets rather than following map order.
+	sort.Strings(sortedTargets)
+	sort.SliceStable(sortedTargets, func(i, j int) bool { return cardFrequency[sortedTargets[i]] > cardFrequency[sortedTargets[j]] })
+
+	var patientTargets []string
+	for _, card := range sortedTargets {
+		if !ai.recentSurgicalTargets.Contains(card) {
+			patientTargets = append(patientTargets, card)
+		}
+	}
+	if len(patientTargets) == 0 {
+		patientTargets = sortedTargets
+	}
+
+	topTargets := patientTargets
+	if len(topTargets) > 3 {
+		topTargets = topTargets[:3]
+	}
+
+	frequent := WeightedChooser{Rng: ai.rng, Score: func(card string) float64 { return float64(cardFrequency[card]) }}
+	targetCard := frequent.Choose(topTargets)
+	log.Infof("[%s] Strategy: SURGICAL STRIKE. Top patient targets: %v. Targeting '%s'.", colorizeCard(ai.name), topTargets, targetCard)
+	ai.recentSurgicalTargets.Push(targetCard)
+	return ai._buildSuggestionAroundTarget(targetCard), true
+}
+
+// --- Strategy Bandit ---
+
+// banditArm tracks how informative one strategy has been so far this game.
+type banditArm struct {
+	pulls  int
+	reward float64 // Total facts learned from this strategy's suggestions.
+}
+
+// _selectStrategy picks among the applicable strategies with UCB1: each is
+// tried once (in priority order), after which the choice favors strategies
+// that have taught us the most per suggestion while still exploring others.
+func (ai *AdvancedAIBrain) _selectStrategy(applicable []string) string {
+	totalPulls := 0
+	for _, name := range applicable {
+		arm := ai.strategyStats[name]
+		if arm == nil || arm.pulls == 0 {
+			return name
+		}
+		totalPulls += arm.pulls
+	}
+	best, bestScore := applicable[0], math.Inf(-1)
+	for _, name := range applicable {
+		arm := ai.strategyStats[name]
+		score := arm.reward/float64(arm.pulls) + math.Sqrt(2*math.Log(float64(totalPulls))/float64(arm.pulls))
+		if score > bestScore {
+			best, bestScore = name, score
+		}
+	}
+	return best
+}
+
+// _rewardStrategy credits the strategy behind our last suggestion with the
+// number of facts its outcome taught us.
+func (ai *AdvancedAIBrain) _rewardStrategy(factsLearned int) {
+	if ai.lastStrategy == "" {
+		return
+	}
+	arm := ai.strategyStats[ai.lastStrategy]
+	if arm == nil {
+		arm = &banditArm{}
+		ai.strategyStats[ai.lastStrategy] = arm
+	}
+	arm.pulls++
+	arm.reward += float64(factsLearned)
+	log.Debugf("[%s's Brain] %s taught me %d facts (avg %.2f over %d).", ai.name, ai.lastStrategy, factsLearned, arm.reward/float64(arm.pulls), arm.pulls)
+	ai.lastStrategy = ""
+}
+
+// _knownCellCount counts the notes cells that are no longer Maybe.
+func (ai *AdvancedAIBrain) _knownCellCount() int {
+	known := 0
+	for _, locations := range ai.knowledge {
+		for _, status := range locations {
+			if status != StatusMaybe {
+				known++
+			}
+		}
+	}
+	return known
+}
+
+func (ai *AdvancedAIBrain) ShouldAccuse() map[string]string {
+	if bad := ai.Contradictions(); len(bad) > 0 {
+		// A contradiction means a mistaken entry; nothing derived from it is
+		// safe. It stays until the entry is fixed, so only warn about new ones.
+		fresh := make(map[string]string)
+		for card, why := range bad {
+			if !ai.warnedAbout[card] {
+				fresh[card] = why
+			}
 		}
-		if cat == "rooms" {
-			cardList = ai.config.Rooms
+		if len(fresh) > 0 {
+			log.Warnf("[%s] Not accusing: the notes contradict themselves (%v).", ai.name, fresh)
+		} else {
+			log.Debugf("[%s] Not accusing: the notes still contradict themselves (%v).", ai.name, bad)
+		}
+		if ai.warnedAbout == nil {
+			ai.warnedAbout = make(map[string]bool)
+		}
+		for card := range fresh {
+			ai.warnedAbout[card] = true
 		}
+		return nil
+	}
+	solution := make(map[string]string)
+	for _, cat := range ai.config.CategoryNames() {
+		cardList := ai.config.CardsOf(cat)
+
+		var knownSolutionCard string
 		for _, card := range cardList {
 			if ai.knowledge[card]["solution"] == StatusYes {
-				knownSolutionCards[cat] = card
-				knownCount++
+				knownSolutionCard = card
 				break
 			}
 		}
+
+		if knownSolutionCard != "" {
+			solution[cat] = knownSolutionCard
+		} else {
+			// Not certain; accuse only if the policy says the odds are good enough.
+			return ai._riskyAccusation()
+		}
 	}
-	if knownCount >= 1 {
-		log.Infof("[%s] Strategy: EXPLOIT. I know %d/3 of the solution, testing a theory.", colorizeCard(ai.name), knownCount)
-		return ai._buildExploitSuggestion(knownSolutionCards)
+
+	if len(solution) == len(ai.config.Categories) {
+		log.Debugf("[%s] Finalizing knowledge before accusing.", ai.name)
+		for _, card := range ai.config.AllCards {
+			isSolutionCard := false
+			for _, solCard := range solution {
+				if card == solCard {
+					isSolutionCard = true
+					break
+				}
+			}
+			if !isSolutionCard {
+				ai.knowledge[card]["solution"] = StatusNo
+			}
+		}
+		ai._runDeductionLoop()
+		log.Infof("[%s] is making a confident ACCUSATION: %v", colorizeCard(ai.name), values(solution))
+		return solution
+	}
+	return nil
+}
+
+// AccusationPolicy decides when a brain accuses without being certain. A
+// wrong accusation knocks the player out, so the bar starts high; but every
+// round spent confirming is a round in which an opponent may accuse first,
+// and that risk grows once a game runs past its typical length. The bar is
+// therefore lowered by LateDiscount for each round beyond typicalSolveTurns,
+// down to MinThreshold. The zero value, which new brains start with, only
+// accuses with certainty.
+type AccusationPolicy struct {
+	Threshold    float64 // Combined probability needed early on; 0 or 1 and above means only when certain.
+	LateDiscount float64 // Lowered per late round.
+	MinThreshold float64 // The bar never drops below this.
+}
+
+// NewAccusationPolicy returns a policy that accuses at threshold, easing off
+// late in the game but never below an even chance.
+func NewAccusationPolicy(threshold float64) AccusationPolicy {
+	return AccusationPolicy{Threshold: threshold, LateDiscount: 0.02, MinThreshold: min(threshold, 0.5)}
+}
+
+// Required returns the confidence needed to accuse after turnsSeen turns
+// at a table of players, or a value above 1 if only certainty will do.
+func (p AccusationPolicy) Required(turnsSeen, players int) float64 {
+	if p.Threshold <= 0 || p.Threshold >= 1 {
+		return 2
+	}
+	lateRounds := float64(max(0, turnsSeen-typicalSolveTurns)) / float64(max(1, players))
+	return max(p.MinThreshold, p.Threshold-p.LateDiscount*lateRounds)
+}
+
+// WithAccusationThreshold lets the brain accuse once its best guess at the
+// solution is at least this likely; see AccusationPolicy.
+func (ai *AdvancedAIBrain) WithAccusationThreshold(threshold float64) *AdvancedAIBrain {
+	ai.accusation = NewAccusationPolicy(threshold)
+	return ai
+}
+
+// _riskyAccusation returns the best guess at the solution if the policy
+// considers it likely enough, and nil otherwise. Unlike a certain
+// accusation it leaves the notes untouched.
+func (ai *AdvancedAIBrain) _riskyAccusation() map[string]string {
+	required := ai.accusation.Required(ai.turnsSeen, len(ai.players))
+	if required > 1 {
+		return nil
+	}
+	guess, confidence := ai.BestGuessSolution()
+	if confidence < required {
+		return nil
+	}
+	log.Infof("[%s] is taking a chance with an ACCUSATION at %.0f%% confidence: %v", colorizeCard(ai.name), confidence*100, values(guess))
+	return guess
+}
+
+// --- AI Helper & Deduction Methods ---
+func (ai *AdvancedAIBrain) _buildExplorationSuggestion() map[string]string {
+	suggestion := make(map[string]string)
+	for _, cat := range ai.config.Categories {
+		suggestion[cat.Name] = ai._pickCard(cat.Cards)
+	}
+	return suggestion
+}
+
+// _candidateCards returns the cards worth naming from a category: solution
+// maybes not in our hand, else any card not in our hand, else the whole list.
+func (ai *AdvancedAIBrain) _candidateCards(cardList []string) []string {
+	var maybes []string
+	for _, card := range cardList {
+		if _, inHand := ai.hand[card]; !inHand && ai.knowledge[card]["solution"] == StatusMaybe {
+			maybes = append(maybes, card)
+		}
+	}
+	if len(maybes) > 0 {
+		return maybes
 	}
 
-	// Priority 2: Surgical Strike
-	if len(ai.unresolvedSuggestions) > 0 {
-		cardFrequency := make(map[string]int)
+	// Fallback: any card not in our hand.
+	var notMyCards []string
+	for _, card := range cardList {
+		if _, inHand := ai.hand[card]; !inHand {
+			notMyCards = append(notMyCards, card)
+		}
+	}
+	if len(notMyCards) > 0 {
+		return notMyCards
+	}
+
+	// Last resort fallback: any card from the list.
+	return cardList
+}
+
+// _mrvTarget picks the unsolved category with the fewest solution candidates
+// and, within it, the candidate with the most unknown locations: if it turns
+// out to be held, that many cells are settled at once. Ties go to the
+// category listed first in the config and to the alphabetically first card,
+// so the choice uses no randomness.
+func (ai *AdvancedAIBrain) _mrvTarget(knowns map[string]string) (category, card string) {
+	fewest := 0
+	for _, cat := range ai.config.CategoryNames() {
+		if _, solved := knowns[cat]; solved {
+			continue
+		}
+		if n := len(ai._solutionCandidates(cat)); n > 0 && (category == "" || n < fewest) {
+			category, fewest = cat, n
+		}
+	}
+	if category == "" {
+		return "", ""
+	}
+	candidates := ai._solutionCandidates(category)
+	sort.Strings(candidates)
+	bestMaybes := -1
+	for _, c := range candidates {
+		if _, inHand := ai.hand[c]; inHand {
+			continue
+		}
+		maybes := 0
+		for _, status := range ai.knowledge[c] {
+			if status == StatusMaybe {
+				maybes++
+			}
+		}
+		if maybes > bestMaybes {
+			card, bestMaybes = c, maybes
+		}
+	}
+	return category, card
+}
+
+// _pickCard picks a valid card for a category at random, favoring cards
+// with few locations left to rule out, since those are the likeliest to be
+// settled by the answer. It returns "" for an empty category;
+// MakeSuggestion's completeness check catches that.
+func (ai *AdvancedAIBrain) _pickCard(cardList []string) string {
+	candidates := ai._candidateCards(cardList)
+	if len(candidates) == 0 {
+		return ""
+	}
+	settled := WeightedChooser{Rng: ai.rng, Score: func(card string) float64 {
+		return 1 / float64(max(1, ai._openLocations(card)))
+	}}
+	return settled.Choose(candidates)
+}
+
+// WithExploitMRV makes Exploit name its most telling card in the unsolved
+// category with the fewest candidates left (minimum remaining values). It is
+// off by default so seeded games replay as before.
+func (ai *AdvancedAIBrain) WithExploitMRV(on bool) *AdvancedAIBrain {
+	ai.exploitMRV = on
+	return ai
+}
+
+func (ai *AdvancedAIBrain) _buildExploitSuggestion(knowns map[string]string) map[string]string {
+	suggestion := make(map[string]string)
+	if ai.exploitMRV {
+		if cat, card := ai._mrvTarget(knowns); card != "" {
+			suggestion[cat] = card
+		}
+	}
+	for _, cat := range ai.config.CategoryNames() {
+		if _, chosen := suggestion[cat]; chosen {
+			continue
+		}
+		if card, ok := knowns[cat]; ok {
+			// If we know the solution for this category, use it.
+			suggestion[cat] = card
+		} else {
+			// Otherwise, robustly pick a card from the correct list.
+			suggestion[cat] = ai._pickCard(ai.config.CardsOf(cat))
+		}
+	}
+	return suggestion
+}
+
+func (ai *AdvancedAIBrain) _buildSuggestionAroundTarget(targetCard string) map[string]string {
+	suggestion := make(map[string]string)
+	targetCategory := ai.config.CardToType[targetCard]
+	suggestion[targetCategory] = targetCard
+
+	myHandSlice := ai.Hand()
+	ai.rng.Shuffle(len(myHandSlice), func(i, j int) { myHandSlice[i], myHandSlice[j] = myHandSlice[j], myHandSlice[i] })
+
+	for _, card := range myHandSlice {
+		if len(suggestion) == len(ai.config.Categories) {
+			break
+		}
+		cat := ai.config.CardToType[card]
+		if _, exists := suggestion[cat]; !exists {
+			suggestion[cat] = card
+		}
+	}
+	if len(suggestion) < len(ai.config.Categories) {
+		exploreSuggestion := ai._buildExplorationSuggestion()
+		for cat, card := range exploreSuggestion {
+			if _, exists := suggestion[cat]; !exists {
+				suggestion[cat] = card
+			}
+		}
+	}
+	return suggestion
+}
+
+// SolutionDistribution returns, for each card in a category, the probability
+// that it is the solution card. Each unknown card is weighted by its chance of
+// being in the solution if all its remaining locations were equally likely,
+// and the weights are normalized so the category sums to 1.
+func (ai *AdvancedAIBrain) SolutionDistribution(category string) map[string]float64 {
+	dist := make(map[string]float64)
+	total := 0.0
+	for _, card := range ai.config.CardsOf(category) {
+		switch ai.knowledge[card]["solution"] {
+		case StatusYes:
+			// A known solution card takes all the mass.
+			for _, other := range ai.config.CardsOf(category) {
+				dist[other] = 0
+			}
+			dist[card] = 1
+			return dist
+		case StatusMaybe:
+			maybes := 0
+			for _, status := range ai.knowledge[card] {
+				if status == StatusMaybe {
+					maybes++
+				}
+			}
+			dist[card] = 1 / float64(maybes)
+			total += dist[card]
+		default:
+			dist[card] = 0
+		}
+	}
+	if total > 0 {
+		for card := range dist {
+			dist[card] /= total
+		}
+	}
+	return dist
+}
+
+// Probabilities estimates, for every card, the chance that it sits in each
+// location. A known card is certain. An unknown card starts out equally
+// likely in each location still open to it; each open mystery then raises
+// the disprover's share to what it would be if they were equally likely to
+// hold any of the mystery's cards, and the other locations share the rest.
+func (ai *AdvancedAIBrain) Probabilities() map[string]map[string]float64 {
+	locations := append(append([]string{}, ai.players...), "solution")
+	probs := make(map[string]map[string]float64)
+	for _, card := range ai.config.AllCards {
+		probs[card] = make(map[string]float64)
+		var open []string
+		for _, loc := range locations {
+			if ai.knowledge[card][loc] == StatusYes {
+				open = []string{loc}
+				break
+			}
+			if ai.knowledge[card][loc] == StatusMaybe {
+				open = append(open, loc)
+			}
+		}
+		if len(open) == 0 {
+			continue // Contradictory notes; nothing sensible to say.
+		}
+		for _, loc := range open {
+			probs[card][loc] = 1 / float64(len(open))
+		}
+		if len(open) == 1 {
+			continue
+		}
+
+		boosted := make(map[string]bool)
 		for _, mystery := range ai.unresolvedSuggestions {
-			for card := range mystery.PossibleCards {
-				cardFrequency[card]++
+			if _, ok := mystery.PossibleCards[card]; !ok || probs[card][mystery.Disprover] == 0 {
+				continue
+			}
+			p := probs[card][mystery.Disprover]
+			probs[card][mystery.Disprover] = 1 - (1-p)*(1-1/float64(len(mystery.PossibleCards)))
+			boosted[mystery.Disprover] = true
+		}
+		if len(boosted) == 0 {
+			continue
+		}
+		boostedMass, restMass := 0.0, 0.0
+		for _, loc := range open {
+			if boosted[loc] {
+				boostedMass += probs[card][loc]
+			} else {
+				restMass += probs[card][loc]
+			}
+		}
+		if boostedMass >= 1 || restMass == 0 {
+			// The boosted locations crowd everything else out.
+			for _, loc := range open {
+				if boosted[loc] {
+					probs[card][loc] /= boostedMass
+				} else {
+					probs[card][loc] = 0
+				}
+			}
+			continue
+		}
+		for _, loc := range open {
+			if !boosted[loc] {
+				probs[card][loc] *= (1 - boostedMass) / restMass
+			}
+		}
+	}
+	return probs
+}
+
+// BestGuessSolution returns the most likely card in each category along with
+// the combined probability of that guess.
+func (ai *AdvancedAIBrain) BestGuessSolution() (map[string]string, float64) {
+	guess := make(map[string]string)
+	confidence := 1.0
+	for _, cat := range ai.config.CategoryNames() {
+		dist := ai.SolutionDistribution(cat)
+		best, bestP := "", -1.0
+		for _, card := range ai.config.CardsOf(cat) {
+			if dist[card] > bestP {
+				best, bestP = card, dist[card]
+			}
+		}
+		guess[cat] = best
+		confidence *= bestP
+	}
+	return guess, confidence
+}
+
+// typicalSolveTurns is roughly how many turns (counting every player's) an
+// all-AI game of four takes to reach an accusation; see the benchmark command.
+const typicalSolveTurns = 22
+
+// LuckEstimate gives a light-hearted verdict on whether we are ahead of or
+// behind an average player. It compares how much of the solution space our
+// hand ruled out against a typical hand of the same size, and how far the
+// space has shrunk since then against a typical pace of play. A hand that
+// covers whole categories apart from their solution card is well ahead. Notes
+// that contradict themselves get no verdict, and so does a hand holding every
+// card of a category, which no real deal can produce.
+func (ai *AdvancedAIBrain) LuckEstimate() string {
+	if len(ai.Contradictions()) > 0 {
+		return "No verdict: the notes contradict themselves. Check the log for a mistake."
+	}
+	known := ai._knownSolutionCards()
+	typical, afterHand, now := 1.0, 1.0, 1.0
+	for _, cat := range ai.config.CategoryNames() {
+		cards := ai.config.CardsOf(cat)
+		inHand, open := 0, 0
+		for _, card := range cards {
+			if _, ok := ai.hand[card]; ok {
+				inHand++
+			}
+			if ai.knowledge[card]["solution"] != StatusNo {
+				open++
 			}
 		}
-		if len(cardFrequency) > 0 {
-			var sortedTargets []string
-			for card := range cardFrequency {
-				sortedTargets = append(sortedTargets, card)
+		if known[cat] != "" {
+			open = 1
+		}
+		share := float64(len(cards)) / float64(len(ai.config.AllCards))
+		typical *= math.Max(1, float64(len(cards))-share*float64(len(ai.hand)))
+		afterHand *= float64(len(cards) - inHand)
+		now *= float64(open)
+	}
+	if afterHand == 0 || now == 0 {
+		// A hand holding a whole category, or notes leaving no candidate.
+		return "No verdict: these notes leave no possible solution."
+	}
+
+	// Both terms are in "natural log of solution candidates" units.
+	dealLuck := math.Log(typical / afterHand)
+	progress := math.Log(afterHand / now)
+	expectedProgress := math.Log(afterHand) * math.Min(1, float64(ai.turnsSeen)/typicalSolveTurns)
+	playLuck := progress - expectedProgress
+	score := dealLuck + playLuck
+
+	var verdict string
+	switch {
+	case now == 1:
+		verdict = "Solved! Luck no longer matters."
+	case score > 0.7:
+		verdict = "Well ahead of an average player."
+	case score > 0.3:
+		verdict = "A little ahead of an average player."
+	case score < -0.7:
+		verdict = "Well behind an average player."
+	case score < -0.3:
+		verdict = "A little behind an average player."
+	default:
+		verdict = "Right about where an average player would be."
+	}
+	deal := "an ordinary deal"
+	if dealLuck > 0.3 {
+		deal = "a lucky deal"
+	} else if dealLuck < -0.3 {
+		deal = "an unlucky deal"
+	}
+	pace := "on pace"
+	if playLuck > 0.3 {
+		pace = "ahead of pace"
+	} else if playLuck < -0.3 {
+		pace = "behind pace"
+	}
+	return fmt.Sprintf("%s (%s; %d of %.0f solutions left after %d turns, %s)", verdict, deal, int(now), afterHand, ai.turnsSeen, pace)
+}
+
+// MostValuableFact finds the single unknown fact that would set off the most
+// further deductions if we learned it. A fact here is "location does not hold
+// card", which is what a player passing on a suggestion tells everyone. Each
+// candidate is tried on a clone, so the brain itself is left untouched. It
+// returns an empty card if no fact leads anywhere.
+func (ai *AdvancedAIBrain) MostValuableFact() (card, location string, impact int) {
+	// The tentative deductions are not news; keep them out of the log.
+	quietly(func() {
+		for _, c := range ai.config.AllCards {
+			for _, loc := range append(append([]string{}, ai.players...), "solution") {
+				if ai.knowledge[c][loc] != StatusMaybe {
+					continue
+				}
+				trial := ai.Clone()
+				trial.knowledge[c][loc] = StatusNo
+				base := trial._knownCellCount()
+				trial._runDeductionLoop()
+				if gained := trial._knownCellCount() - base; gained > impact {
+					card, location, impact = c, loc, gained
+				}
 			}
-			sort.Slice(sortedTargets, func(i, j int) bool { return cardFrequency[sortedTargets[i]] > cardFrequency[sortedTargets[j]] })
+		}
+	})
+	return card, location, impact
+}
 
-			var patientTargets []string
-			for _, card := range sortedTargets {
-				if !ai.recentSurgicalTargets.Contains(card) {
-					patientTargets = append(patientTargets, card)
-				}
-			}
-			if len(patientTargets) == 0 {
-				patientTargets = sortedTargets
-			}
+// --- Suggestion Enumeration ---
 
-			topTargets := patientTargets
-			if len(topTargets) > 3 {
-				topTargets = topTargets[:3]
-			}
+// SuggestionOption is one candidate suggestion together with the strategy that
+// would produce it and a rough estimate of how much it could teach us.
+type SuggestionOption struct {
+	Cards    map[string]string
+	Strategy string
+	InfoGain int
+}
 
-			if len(topTargets) > 0 {
-				targetCard := topTargets[rand.Intn(len(topTargets))]
-				log.Infof("[%s] Strategy: SURGICAL STRIKE. Top patient targets: %v. Targeting '%s'.", colorizeCard(ai.name), topTargets, targetCard)
-				ai.recentSurgicalTargets.Push(targetCard)
-				return ai._buildSuggestionAroundTarget(targetCard)
-			}
-		}
-	}
+const (
+	StrategyExploit  = "Exploit"
+	StrategySurgical = "Surgical Strike"
+	StrategyExplore  = "Explore"
+	StrategyFocus    = "Focus"
+	StrategyBluff    = "Bluff"
+	StrategyInfoGain = "Information Gain"
+)
 
-	// Priority 3: Explore
-	log.Infof("[%s] Strategy: EXPLORE. Gathering new information.", colorizeCard(ai.name))
-	return ai._buildExplorationSuggestion()
+// bluffOdds is the chance, one in bluffOdds, that a brain allowed to bluff
+// considers it on a given turn.
+const bluffOdds = 4
+
+// DefaultStrategies is the priority order a new brain uses. Focus and Bluff
+// are opt-in; see SetStrategies.
+var DefaultStrategies = []string{StrategyExploit, StrategySurgical, StrategyExplore}
+
+// AIOptions tune how new brains play. The zero value is the standard brain.
+// They travel with the config rather than in package variables, so tests and
+// server sessions never see each other's settings.
+type AIOptions struct {
+	Strategies []string         // Priority order, as in SetStrategies; nil means DefaultStrategies.
+	ExploitMRV bool             // See WithExploitMRV.
+	Patience   *int             // See WithPatience; nil means DefaultPatience.
+	Accusation AccusationPolicy // The zero value only accuses with certainty.
+	Show       ShowStrategy     // How to pick a card to show; nil means DefaultShowStrategy.
 }
 
-func (ai *AdvancedAIBrain) ShouldAccuse() map[string]string {
-	solution := make(map[string]string)
-	for _, cat := range []string{"suspects", "weapons", "rooms"} {
-		cardList := ai.config.Suspects
-		if cat == "weapons" {
-			cardList = ai.config.Weapons
-		}
-		if cat == "rooms" {
-			cardList = ai.config.Rooms
-		}
+// NewBrain returns a brain that plays by these options.
+func (o AIOptions) NewBrain() *AdvancedAIBrain {
+	ai := NewAdvancedAIBrain()
+	if o.Strategies != nil {
+		ai.SetStrategies(slices.Clone(o.Strategies))
+	}
+	if o.Patience != nil {
+		ai.WithPatience(*o.Patience)
+	}
+	ai.accusation = o.Accusation
+	ai.SetShowStrategy(o.Show)
+	return ai.WithExploitMRV(o.ExploitMRV)
+}
 
-		var knownSolutionCard string
-		for _, card := range cardList {
-			if ai.knowledge[card]["solution"] == StatusYes {
-				knownSolutionCard = card
-				break
+// SetStrategies chooses which strategies the brain may use, in priority order.
+// Explore is always available as a fallback.
+func (ai *AdvancedAIBrain) SetStrategies(names []string) { ai.strategies = names }
+
+// WithStrategies is SetStrategies for building brains in one expression, as
+// in NewAdvancedAIBrain().WithStrategies(StrategyExplore). With no names the
+// brain uses DefaultStrategies.
+func (ai *AdvancedAIBrain) WithStrategies(names ...string) *AdvancedAIBrain {
+	if len(names) == 0 {
+		names = nil
+	}
+	ai.SetStrategies(names)
+	return ai
+}
+
+// UsefulSuggestions enumerates every suggestion the strategies consider useful
+// this turn, ranked by estimated information gain. It does not change any state.
+func (ai *AdvancedAIBrain) UsefulSuggestions() []SuggestionOption {
+	var options []SuggestionOption
+	seen := make(map[string]bool)
+	add := func(strategy string, suggestions []map[string]string) {
+		for _, s := range suggestions {
+			key := strings.Join(values(s), "|")
+			if seen[key] || !isCompleteSuggestion(ai.config, s) {
+				continue
 			}
+			seen[key] = true
+			options = append(options, SuggestionOption{Cards: s, Strategy: strategy, InfoGain: ai._estimateInfoGain(s)})
 		}
+	}
+	add(StrategyExploit, ai._enumerateExploitSuggestions())
+	add(StrategySurgical, ai._enumerateSurgicalSuggestions())
+	add(StrategyFocus, ai._enumerateFocusSuggestions())
+	add(StrategyExplore, ai._enumerateExplorationSuggestions())
 
-		if knownSolutionCard != "" {
-			solution[cat] = knownSolutionCard
-		} else {
-			return nil // If any category isn't a "Yes", we can't accuse.
+	sort.SliceStable(options, func(i, j int) bool {
+		if options[i].InfoGain != options[j].InfoGain {
+			return options[i].InfoGain > options[j].InfoGain
 		}
-	}
+		return strings.Join(values(options[i].Cards), "|") < strings.Join(values(options[j].Cards), "|")
+	})
+	return options
+}
 
-	if len(solution) == 3 {
-		log.Debugf("[%s] Finalizing knowledge before accusing.", ai.name)
-		for _, card := range ai.config.AllCards {
-			isSolutionCard := false
-			for _, solCard := range solution {
-				if card == solCard {
-					isSolutionCard = true
-					break
+// Rationale says in one line what a suggestion from UsefulSuggestions hopes
+// to learn, in terms of the strategy that proposed it.
+func (ai *AdvancedAIBrain) Rationale(opt SuggestionOption) string {
+	known := ai._knownSolutionCards()
+	var fixed, probed []string
+	for _, cat := range ai.config.CategoryNames() {
+		if card := opt.Cards[cat]; known[cat] == card {
+			fixed = append(fixed, card)
+		} else {
+			probed = append(probed, card)
+		}
+	}
+	if len(probed) == 0 {
+		return "Names the solution you have already found."
+	}
+	switch opt.Strategy {
+	case StrategyExploit:
+		return fmt.Sprintf("Holds the known %s to test %s.", strings.Join(fixed, " and "), strings.Join(probed, " and "))
+	case StrategySurgical:
+		target, most := "", 0
+		for _, cat := range ai.config.CategoryNames() {
+			n := 0
+			for _, mystery := range ai.unresolvedSuggestions {
+				if _, ok := mystery.PossibleCards[opt.Cards[cat]]; ok {
+					n++
 				}
 			}
-			if !isSolutionCard {
-				ai.knowledge[card]["solution"] = StatusNo
+			if n > most {
+				target, most = opt.Cards[cat], n
 			}
 		}
-		ai._runDeductionLoop()
-		log.Infof("[%s] is making a confident ACCUSATION: %v", colorizeCard(ai.name), values(solution))
-		return solution
+		if most == 1 {
+			return fmt.Sprintf("Targets %s, part of an open mystery, to learn who showed it.", target)
+		}
+		return fmt.Sprintf("Targets %s, part of %d open mysteries, to learn who showed it.", target, most)
+	case StrategyFocus:
+		return fmt.Sprintf("Narrows down the %s, the category closest to solved.", ai._focusCategory())
 	}
-	return nil
+	return fmt.Sprintf("Probes %s, touching %d unknown cells and open mysteries.", strings.Join(probed, ", "), opt.InfoGain)
 }
 
-// --- AI Helper & Deduction Methods ---
-func (ai *AdvancedAIBrain) _buildExplorationSuggestion() map[string]string {
-	suggestion := make(map[string]string)
-
-	// This is a helper function to pick a valid card for a category.
-	pickCard := func(cardList []string) string {
-		var maybes []string
-		for _, card := range cardList {
-			if _, inHand := ai.hand[card]; !inHand && ai.knowledge[card]["solution"] == StatusMaybe {
-				maybes = append(maybes, card)
+// _estimateInfoGain counts the unknown cells a suggestion touches, plus one for
+// every open mystery each card takes part in.
+func (ai *AdvancedAIBrain) _estimateInfoGain(suggestion map[string]string) int {
+	gain := 0
+	for _, card := range suggestion {
+		for _, status := range ai.knowledge[card] {
+			if status == StatusMaybe {
+				gain++
 			}
 		}
-		if len(maybes) > 0 {
-			return maybes[rand.Intn(len(maybes))]
-		}
-
-		// Fallback: pick any card not in our hand.
-		var notMyCards []string
-		for _, card := range cardList {
-			if _, inHand := ai.hand[card]; !inHand {
-				notMyCards = append(notMyCards, card)
+		for _, mystery := range ai.unresolvedSuggestions {
+			if _, ok := mystery.PossibleCards[card]; ok {
+				gain++
 			}
 		}
-		if len(notMyCards) > 0 {
-			return notMyCards[rand.Intn(len(notMyCards))]
-		}
-
-		// Last resort fallback: pick any card from the list.
-		return cardList[rand.Intn(len(cardList))]
 	}
-
-	suggestion["suspects"] = pickCard(ai.config.Suspects)
-	suggestion["weapons"] = pickCard(ai.config.Weapons)
-	suggestion["rooms"] = pickCard(ai.config.Rooms)
-
-	return suggestion
+	return gain
 }
 
-func (ai *AdvancedAIBrain) _buildExploitSuggestion(knowns map[string]string) map[string]string {
+// _buildInfoGainSuggestion explores like Explore, but names the candidates
+// closest to being pinned down: in each category it draws among the cards
+// with the fewest locations left to rule out. Such a card is either shown,
+// settling its owner, or passed on by everyone who might hold it, which puts
+// it in the solution; a card that could be anywhere rarely settles either
+// way. Favoring the least certain cards instead did worse than plain
+// Explore, and so did always naming the first best card. Open mysteries are
+// left to Surgical Strike.
+func (ai *AdvancedAIBrain) _buildInfoGainSuggestion() map[string]string {
 	suggestion := make(map[string]string)
-
-	// This is a helper function to robustly pick a card for a category.
-	pickCard := func(cardList []string) string {
-		var maybes []string
-		for _, card := range cardList {
-			if _, inHand := ai.hand[card]; !inHand && ai.knowledge[card]["solution"] == StatusMaybe {
-				maybes = append(maybes, card)
-			}
-		}
-		if len(maybes) > 0 {
-			return maybes[rand.Intn(len(maybes))]
+	for _, cat := range ai.config.Categories {
+		candidates := ai._candidateCards(cat.Cards)
+		if len(candidates) == 0 {
+			suggestion[cat.Name] = ai._pickCard(cat.Cards)
+			continue
 		}
+		suggestion[cat.Name] = RandomChooser{ai.rng}.Choose(ai._leastOpenCards(candidates))
+	}
+	log.Infof("[%s] Strategy: INFORMATION GAIN. Naming cards closest to being pinned down.", colorizeCard(ai.name))
+	return suggestion
+}
 
-		var notMyCards []string
-		for _, card := range cardList {
-			if _, inHand := ai.hand[card]; !inHand {
-				notMyCards = append(notMyCards, card)
-			}
+// _openLocations counts the locations that may still hold card.
+func (ai *AdvancedAIBrain) _openLocations(card string) int {
+	open := 0
+	for _, status := range ai.knowledge[card] {
+		if status == StatusMaybe {
+			open++
 		}
-		if len(notMyCards) > 0 {
-			return notMyCards[rand.Intn(len(notMyCards))]
+	}
+	return open
+}
+
+// _leastOpenCards returns the cards with the fewest unknown locations, in
+// their original order.
+func (ai *AdvancedAIBrain) _leastOpenCards(cards []string) []string {
+	var least []string
+	fewest := 0
+	for _, card := range cards {
+		maybes := ai._openLocations(card)
+		switch {
+		case least == nil || maybes < fewest:
+			least, fewest = []string{card}, maybes
+		case maybes == fewest:
+			least = append(least, card)
 		}
+	}
+	return least
+}
 
-		return cardList[rand.Intn(len(cardList))]
+func (ai *AdvancedAIBrain) _enumerateExplorationSuggestions() []map[string]string {
+	candidates := make(map[string][]string)
+	for _, cat := range ai.config.CategoryNames() {
+		candidates[cat] = ai._candidateCards(ai.config.CardsOf(cat))
 	}
+	return ai.combineSuggestions(candidates)
+}
 
-	for _, cat := range []string{"suspects", "weapons", "rooms"} {
-		if card, ok := knowns[cat]; ok {
-			// If we know the solution for this category, use it.
-			suggestion[cat] = card
+func (ai *AdvancedAIBrain) _enumerateExploitSuggestions() []map[string]string {
+	known := ai._knownSolutionCards()
+	if len(known) == 0 {
+		return nil
+	}
+	candidates := make(map[string][]string)
+	for _, cat := range ai.config.CategoryNames() {
+		if card, ok := known[cat]; ok {
+			candidates[cat] = []string{card}
 		} else {
-			// Otherwise, robustly pick a card from the correct list.
-			var cardList []string
-			switch cat {
-			case "suspects":
-				cardList = ai.config.Suspects
-			case "weapons":
-				cardList = ai.config.Weapons
-			case "rooms":
-				cardList = ai.config.Rooms
-			}
-			suggestion[cat] = pickCard(cardList)
+			candidates[cat] = ai._candidateCards(ai.config.CardsOf(cat))
 		}
 	}
-	return suggestion
+	return ai.combineSuggestions(candidates)
 }
 
-func (ai *AdvancedAIBrain) _buildSuggestionAroundTarget(targetCard string) map[string]string {
-	suggestion := make(map[string]string)
-	targetCategory := ai.config.CardToType[targetCard]
-	suggestion[targetCategory] = targetCard
-
-	var myHandSlice []string
-	for card := range ai.hand {
-		myHandSlice = append(myHandSlice, card)
+func (ai *AdvancedAIBrain) _enumerateFocusSuggestions() []map[string]string {
+	focus := ai._focusCategory()
+	if focus == "" {
+		return nil
+	}
+	known := ai._knownSolutionCards()
+	candidates := map[string][]string{focus: ai._solutionCandidates(focus)}
+	for _, cat := range ai.config.CategoryNames() {
+		if cat == focus {
+			continue
+		}
+		if card, ok := known[cat]; ok {
+			candidates[cat] = []string{card}
+			continue
+		}
+		for _, card := range ai.config.CardsOf(cat) {
+			if _, inHand := ai.hand[card]; inHand {
+				candidates[cat] = append(candidates[cat], card)
+			}
+		}
+		if len(candidates[cat]) == 0 {
+			candidates[cat] = ai._candidateCards(ai.config.CardsOf(cat))
+		}
 	}
-	rand.Shuffle(len(myHandSlice), func(i, j int) { myHandSlice[i], myHandSlice[j] = myHandSlice[j], myHandSlice[i] })
+	return ai.combineSuggestions(candidates)
+}
 
-	for _, card := range myHandSlice {
-		if len(suggestion) == 3 {
-			break
+func (ai *AdvancedAIBrain) _enumerateSurgicalSuggestions() []map[string]string {
+	targets := make(map[string]struct{})
+	for _, mystery := range ai.unresolvedSuggestions {
+		for card := range mystery.PossibleCards {
+			targets[card] = struct{}{}
 		}
-		cat := ai.config.CardToType[card]
-		if _, exists := suggestion[cat]; !exists {
-			suggestion[cat] = card
+	}
+	var suggestions []map[string]string
+	for _, target := range sortedKeys(targets) {
+		targetCategory := ai.config.CardToType[target]
+		candidates := map[string][]string{targetCategory: {target}}
+		// Fill the other slots with our own cards, as _buildSuggestionAroundTarget does.
+		for _, cat := range ai.config.CategoryNames() {
+			if cat == targetCategory {
+				continue
+			}
+			for _, card := range ai.config.CardsOf(cat) {
+				if _, inHand := ai.hand[card]; inHand {
+					candidates[cat] = append(candidates[cat], card)
+				}
+			}
+			if len(candidates[cat]) == 0 {
+				candidates[cat] = ai._candidateCards(ai.config.CardsOf(cat))
+			}
 		}
+		suggestions = append(suggestions, ai.combineSuggestions(candidates)...)
 	}
-	if len(suggestion) < 3 {
-		exploreSuggestion := ai._buildExplorationSuggestion()
-		for cat, card := range exploreSuggestion {
-			if _, exists := suggestion[cat]; !exists {
+	return suggestions
+}
+
+// combineSuggestions builds every suggestion that takes one card per category
+// from the given candidate lists.
+func (ai *AdvancedAIBrain) combineSuggestions(candidates map[string][]string) []map[string]string {
+	result := []map[string]string{{}}
+	for _, cat := range ai.config.CategoryNames() {
+		var next []map[string]string
+		for _, partial := range result {
+			for _, card := range candidates[cat] {
+				suggestion := maps.Clone(partial)
 				suggestion[cat] = card
+				next = append(next, suggestion)
 			}
 		}
+		result = next
 	}
-	return suggestion
+	return result
 }
 
-func (ai *AdvancedAIBrain) _markCardLocation(card, location string) {
+// Deduction rules, as credited for placing the last solution card.
+const (
+	RuleOwnHand             = "own hand"
+	RuleRevealed            = "revealed card"
+	RuleUndisproved         = "undisproved suggestion"
+	RuleMystery             = "mystery resolution"
+	RuleCardElimination     = "card elimination"
+	RuleSolutionElimination = "solution elimination"
+	RuleHandSize            = "hand size"
+	RuleJointMysteries      = "joint mysteries"
+)
+
+// _markCardLocation records that location holds card. rule names the
+// deduction that established it; if this completes the solution, the rule is
+// remembered as the winning deduction.
+func (ai *AdvancedAIBrain) _markCardLocation(card, location, rule string) {
 	// --- THE CORRECTED, ROBUST DEBUGGING CHECK ---
 	// It correctly checks the 'card' variable.
 	if _, isValidCard := ai.config.CardToType[card]; !isValidCard {
@@ -528,8 +2520,53 @@ func (ai *AdvancedAIBrain) _markCardLocation(card, location string) {
 		ai.knowledge[card][loc] = StatusNo
 	}
 	ai.knowledge[card][location] = StatusYes
+	ai.reasoning = append(ai.reasoning, Deduction{Card: card, Location: location, Reason: rule, Entry: len(ai.history)})
+	if location == "solution" && ai.solvedBy == "" && len(ai._knownSolutionCards()) == len(ai.config.Categories) {
+		ai.solvedBy = rule
+		log.Debugf("[%s's Brain] completed the solution by %s.", ai.name, rule)
+	}
+}
+
+// Deduction records how a card was placed: Reason is one of the Rule
+// constants, and Entry is how many entries had been logged at the time, so
+// 0 means it was known from the start (e.g. our own hand).
+type Deduction struct {
+	Card, Location, Reason string
+	Entry                  int
+}
+
+// Reasoning returns every placement made since the deal, oldest first.
+func (ai *AdvancedAIBrain) Reasoning() []Deduction { return slices.Clone(ai.reasoning) }
+
+// Explain returns the deductions behind card's location: its own placement
+// and, if it was placed in the solution by elimination, the placements that
+// ruled out the rest of its category. It is empty if the card is unplaced or
+// was placed without a record, e.g. in notes loaded from a file.
+func (ai *AdvancedAIBrain) Explain(card string) []Deduction {
+	var own *Deduction
+	for i := range ai.reasoning {
+		if ai.reasoning[i].Card == card {
+			own = &ai.reasoning[i]
+		}
+	}
+	if own == nil {
+		return nil
+	}
+	chain := []Deduction{*own}
+	if own.Reason == RuleSolutionElimination {
+		for _, d := range ai.reasoning {
+			if d.Card != card && ai.config.CardToType[d.Card] == ai.config.CardToType[card] {
+				chain = append(chain, d)
+			}
+		}
+	}
+	return chain
 }
 
+// WinningDeduction names the rule that placed the last solution card, or is
+// empty while the solution is still incomplete.
+func (ai *AdvancedAIBrain) WinningDeduction() string { return ai.solvedBy }
+
 func (ai *AdvancedAIBrain) _deduceCardLocationsByElimination() {
 	for _, card := range ai.config.AllCards {
 		known := false
@@ -553,7 +2590,7 @@ func (ai *AdvancedAIBrain) _deduceCardLocationsByElimination() {
 
 		if len(maybes) == 1 {
 			final_location := maybes[0]
-			ai._markCardLocation(card, final_location)
+			ai._markCardLocation(card, final_location, RuleCardElimination)
 		}
 	}
 }
@@ -564,10 +2601,257 @@ func (ai *AdvancedAIBrain) _runDeductionLoop() {
 		ai._pruneAndSolveMysteries()
 		ai._deduceSolutionByElimination()
 		ai._deduceCardLocationsByElimination()
+		ai._deduceFromHandSizes()
+		ai._deduceByGlobalCardCount()
+		ai._solveMysteriesJointly()
 		if fmt.Sprintf("%v", ai.knowledge) == before {
 			break
 		}
 	}
+	if known := ai._knownSolutionCards(); len(known) == len(ai.config.Categories) && !ai.solutionAnnounced {
+		ai.solutionAnnounced = true
+		if ai.OnSolutionFound != nil {
+			ai.OnSolutionFound(known)
+		}
+	}
+	for _, p := range ai.players {
+		if _, _, ok := ai._mysteryHittingSets(p); !ok {
+			log.Warnf("%s %s cannot have shown a card for every suggestion they disproved with only %d cards. Some logged turn is wrong.", makeAiTitle(ai.name), p, ai.handSizes[p])
+		}
+	}
+}
+
+// _mysteryHittingSets finds every way player p's unknown cards could explain
+// all of p's open mysteries within p's hand size: each returned set holds one
+// card from every mystery not already explained by a card p is known to hold.
+// Every minimal set is included. budget is how many of p's cards are still
+// unknown, and ok is false if no set fits, i.e. the notes contradict.
+func (ai *AdvancedAIBrain) _mysteryHittingSets(p string) (sets [][]string, budget int, ok bool) {
+	size, known := ai.handSizes[p]
+	if !known {
+		return nil, 0, true
+	}
+	budget = size
+	for _, card := range ai.config.AllCards {
+		if ai.knowledge[card][p] == StatusYes {
+			budget--
+		}
+	}
+	var open [][]string
+	for _, m := range ai.unresolvedSuggestions {
+		if m.Disprover != p {
+			continue
+		}
+		var cards []string
+		explained := false
+		for _, card := range sortedKeys(m.PossibleCards) {
+			switch ai.knowledge[card][p] {
+			case StatusYes:
+				explained = true
+			case StatusMaybe:
+				cards = append(cards, card)
+			}
+		}
+		if !explained {
+			open = append(open, cards)
+		}
+	}
+
+	var search func(chosen []string)
+	search = func(chosen []string) {
+		for _, cards := range open {
+			hit := false
+			for _, card := range cards {
+				if slices.Contains(chosen, card) {
+					hit = true
+					break
+				}
+			}
+			if hit {
+				continue
+			}
+			if len(chosen) >= budget {
+				return
+			}
+			for _, card := range cards {
+				search(append(slices.Clip(chosen), card))
+			}
+			return
+		}
+		sets = append(sets, chosen)
+	}
+	search(nil)
+	return sets, budget, len(sets) > 0
+}
+
+// _solveMysteriesJointly combines all of a player's mysteries with their hand
+// size. A card in every way of explaining the mysteries must be held; and if
+// every explanation already fills the hand, cards in none of them cannot be.
+func (ai *AdvancedAIBrain) _solveMysteriesJointly() {
+	for _, p := range ai.players {
+		sets, budget, ok := ai._mysteryHittingSets(p)
+		if !ok || len(sets) == 0 || len(sets[0]) == 0 {
+			continue
+		}
+		inAll := make(map[string]int)
+		minSize := budget
+		for _, set := range sets {
+			minSize = min(minSize, len(set))
+			for _, card := range set {
+				inAll[card]++
+			}
+		}
+		for card, n := range inAll {
+			if n == len(sets) && ai.knowledge[card][p] != StatusYes {
+				log.Debugf("[%s's Brain] %s must hold %s to explain all their disprovals.", ai.name, p, card)
+				ai._markCardLocation(card, p, RuleJointMysteries)
+			}
+		}
+		if minSize < budget {
+			continue
+		}
+		for _, card := range ai.config.AllCards {
+			if _, used := inAll[card]; !used && ai.knowledge[card][p] == StatusMaybe {
+				log.Debugf("[%s's Brain] %s's remaining cards are all needed for their disprovals; they cannot hold %s.", ai.name, p, card)
+				ai.knowledge[card][p] = StatusNo
+			}
+		}
+	}
+}
+
+// _deduceFromHandSizes closes out players whose hands are fully accounted
+// for: once a player's known cards fill their hand they hold nothing else,
+// and once their known cards plus possibilities just fill it they hold all
+// of those possibilities.
+func (ai *AdvancedAIBrain) _deduceFromHandSizes() {
+	for _, p := range ai.players {
+		size, ok := ai.handSizes[p]
+		if !ok {
+			continue
+		}
+		var yes, maybe []string
+		for _, card := range ai.config.AllCards {
+			switch ai.knowledge[card][p] {
+			case StatusYes:
+				yes = append(yes, card)
+			case StatusMaybe:
+				maybe = append(maybe, card)
+			}
+		}
+		if len(maybe) == 0 {
+			continue
+		}
+		if len(yes) == size {
+			log.Debugf("[%s's Brain] %s's %d cards are all known; they hold nothing else.", ai.name, p, size)
+			for _, card := range maybe {
+				ai.knowledge[card][p] = StatusNo
+			}
+		} else if len(yes)+len(maybe) == size {
+			log.Debugf("[%s's Brain] %s must hold every card still possible for them: %v.", ai.name, p, maybe)
+			for _, card := range maybe {
+				ai._markCardLocation(card, p, RuleHandSize)
+			}
+		}
+	}
+}
+
+// _deduceByGlobalCardCount uses the fact that every card is in exactly one
+// place, the solution holding one card per category. First, a solved
+// category rules every other card of it out of the solution. Then, for every
+// group of locations whose capacity is known, if the unplaced cards that can
+// only be somewhere in the group exactly fill the group's free slots, no other
+// card can be in any of those locations. A group of one player is the hand
+// size rule; larger groups catch what the per-card and per-player passes miss.
+func (ai *AdvancedAIBrain) _deduceByGlobalCardCount() {
+	for _, cat := range ai.config.Categories {
+		solved := ""
+		for _, card := range cat.Cards {
+			if ai.knowledge[card]["solution"] == StatusYes {
+				solved = card
+			}
+		}
+		if solved == "" {
+			continue
+		}
+		for _, card := range cat.Cards {
+			if card != solved && ai.knowledge[card]["solution"] == StatusMaybe {
+				log.Debugf("[%s's Brain] %s is the solution's %s, so %s is not.", ai.name, solved, cat.Name, card)
+				ai.knowledge[card]["solution"] = StatusNo
+			}
+		}
+	}
+
+	// Free slots per location, and which locations each unplaced card may be in.
+	locations := append(append([]string{}, ai.players...), "solution")
+	free := make([]int, len(locations))
+	var known uint // Bit i is set if location i's capacity is known.
+	for i, loc := range locations {
+		size, ok := ai.handSizes[loc], true
+		if loc == "solution" {
+			size = len(ai.config.Categories)
+		} else {
+			_, ok = ai.handSizes[loc]
+		}
+		if !ok {
+			continue
+		}
+		known |= 1 << i
+		free[i] = size
+		for _, card := range ai.config.AllCards {
+			if ai.knowledge[card][loc] == StatusYes {
+				free[i]--
+			}
+		}
+	}
+	options := make(map[string]uint)
+	for _, card := range ai.config.AllCards {
+		var mask uint
+		placed := false
+		for i, loc := range locations {
+			switch ai.knowledge[card][loc] {
+			case StatusYes:
+				placed = true
+			case StatusMaybe:
+				mask |= 1 << i
+			}
+		}
+		if !placed && mask != 0 {
+			options[card] = mask
+		}
+	}
+
+	for group := uint(1); group < 1<<len(locations); group++ {
+		if group&known != group {
+			continue
+		}
+		slots, confined := 0, 0
+		for i := range locations {
+			if group&(1<<i) != 0 {
+				slots += free[i]
+			}
+		}
+		for _, mask := range options {
+			if mask&group == mask {
+				confined++
+			}
+		}
+		if confined == 0 || confined != slots {
+			continue
+		}
+		for _, card := range ai.config.AllCards {
+			mask, open := options[card]
+			if !open || mask&group == mask || mask&group == 0 {
+				continue
+			}
+			for i, loc := range locations {
+				if group&(1<<i) != 0 && ai.knowledge[card][loc] == StatusMaybe {
+					log.Debugf("[%s's Brain] Other cards already fill %s's free slots, so '%s' is not there.", ai.name, loc, card)
+					ai.knowledge[card][loc] = StatusNo
+				}
+			}
+			options[card] = mask &^ group
+		}
+	}
 }
 
 func (ai *AdvancedAIBrain) _pruneAndSolveMysteries() {
@@ -586,7 +2870,11 @@ func (ai *AdvancedAIBrain) _pruneAndSolveMysteries() {
 		if len(prunedCards) == 1 {
 			card := mapKeys(prunedCards)[0]
 			log.Infof("%s SOLVED A MYSTERY! %s must have shown '%s'.", makeAiTitle(ai.name), colorizeCard(mystery.Disprover), card)
-			ai._markCardLocation(card, mystery.Disprover)
+			isNew := ai.knowledge[card][mystery.Disprover] != StatusYes
+			ai._markCardLocation(card, mystery.Disprover, RuleMystery)
+			if isNew && ai.OnMysterySolved != nil {
+				ai.OnMysterySolved(mystery.Disprover, card)
+			}
 		} else if len(prunedCards) > 1 {
 			remainingMysteries = append(remainingMysteries, mystery)
 		}
@@ -595,14 +2883,8 @@ func (ai *AdvancedAIBrain) _pruneAndSolveMysteries() {
 }
 
 func (ai *AdvancedAIBrain) _deduceSolutionByElimination() {
-	for _, cat := range []string{"suspects", "weapons", "rooms"} {
-		cardList := config.Suspects
-		if cat == "weapons" {
-			cardList = config.Weapons
-		}
-		if cat == "rooms" {
-			cardList = config.Rooms
-		}
+	for _, cat := range ai.config.CategoryNames() {
+		cardList := ai.config.CardsOf(cat)
 		isSolved := false
 		for _, card := range cardList {
 			if ai.knowledge[card]["solution"] == StatusYes {
@@ -620,46 +2902,126 @@ func (ai *AdvancedAIBrain) _deduceSolutionByElimination() {
 			}
 		}
 		if len(maybes) == 1 {
-			ai._markCardLocation(maybes[0], "solution")
+			ai._markCardLocation(maybes[0], "solution", RuleSolutionElimination)
 		}
 	}
 }
 
-// --- Human Player (Placeholder) ---
+// --- Human Player ---
 type HumanPlayer struct {
-	name string
-	cfg  GameConfig
-	hand map[string]struct{}
+	name    string
+	cfg     GameConfig
+	players []string
+	hand    map[string]struct{}
+
+	// controller makes the human's decisions: the terminal, or nobody.
+	controller Controller
+
+	// room is where the human's token is in board mode, or "".
+	room string
+
+	// assistant is an optional shadow co-pilot. It only ever receives what the
+	// human legitimately sees: their hand and the turn info the game passes on.
+	assistant *AdvancedAIBrain
+
+	// err is why the player left the game, e.g. ErrUserQuit; see Err.
+	err error
+}
+
+// NewHumanPlayer creates a human whose decisions come from ctrl. A nil ctrl
+// means NullController.
+func NewHumanPlayer(ctrl Controller) *HumanPlayer {
+	if ctrl == nil {
+		ctrl = NullController{}
+	}
+	return &HumanPlayer{name: "Human", controller: ctrl}
 }
 
-func NewHumanPlayer() *HumanPlayer   { return &HumanPlayer{name: "Human"} }
+// SetController replaces where the player's decisions come from.
+func (h *HumanPlayer) SetController(ctrl Controller) { h.controller = ctrl }
+
 func (h *HumanPlayer) Name() string  { return h.name }
 func (h *HumanPlayer) IsHuman() bool { return true }
 func (h *HumanPlayer) Setup(cfg GameConfig, playerNames []string, myName string) {
 	h.name = myName
 	h.cfg = cfg
+	h.players = append([]string{}, playerNames...)
 	h.hand = make(map[string]struct{})
+	if h.assistant != nil {
+		h.assistant.Setup(cfg, playerNames, myName)
+	}
+}
+
+// EnableAssist attaches a shadow co-pilot. It must be called before the deal.
+func (h *HumanPlayer) EnableAssist() {
+	h.assistant = h.cfg.AI.NewBrain()
+	h.assistant.Setup(h.cfg, h.players, h.name)
+	h.assistant.OnSolutionFound = func(solution map[string]string) {
+		C.Yes.Printf("Your co-pilot has worked out the solution: %v. Accuse on your turn!\n", values(solution))
+	}
 }
+
 func (h *HumanPlayer) ReceiveHand(cards []string) {
 	for _, card := range cards {
 		h.hand[card] = struct{}{}
 	}
+	if h.assistant != nil {
+		h.assistant.ReceiveHand(cards)
+	}
 	C.Info.Printf("\nYour hand: %v\n", cards)
 }
+
+// MakeSuggestion asks the controller for a suggestion. If the controller
+// fails, e.g. with ErrUserQuit, the player has left: it returns nil and Err
+// reports why, so the game can end.
 func (h *HumanPlayer) MakeSuggestion() map[string]string {
-	// In simulation, this would prompt the user.
-	// For now, we return nil as the loop handles this for humans.
-	return nil
+	suggestion, err := h.controller.PromptSuggestion(h)
+	if err != nil {
+		h.err = err
+		return nil
+	}
+	if h.room != "" && suggestion != nil && suggestion[roomCategory] != h.room {
+		C.Warn.Printf("You are in the %s, so your suggestion names it.\n", h.room)
+		suggestion[roomCategory] = h.room
+	}
+	return suggestion
+}
+
+func (h *HumanPlayer) showAdvice() {
+	if h.assistant == nil {
+		C.Warn.Println("No co-pilot available. Start the game with -assist.")
+		return
+	}
+	suggestion := h.assistant.MakeSuggestion()
+	C.Info.Printf("Co-pilot suggests: %v\n", values(suggestion))
+	guess, confidence := h.assistant.BestGuessSolution()
+	C.Info.Printf("Co-pilot's best guess at the solution: %v (%.0f%% confident)\n", values(guess), confidence*100)
+}
+
+// ShouldAccuse asks the controller for an accusation; errors are handled as
+// in MakeSuggestion.
+func (h *HumanPlayer) ShouldAccuse() map[string]string {
+	accusation, err := h.controller.PromptAccusation(h)
+	if err != nil {
+		h.err = err
+		return nil
+	}
+	return accusation
 }
-func (h *HumanPlayer) ShouldAccuse() map[string]string { return nil }
+
+// Err returns why the player left the game, or nil while they are playing.
+func (h *HumanPlayer) Err() error { return h.err }
 func (h *HumanPlayer) ProcessTurnInfo(suggester, disprover, revealedCard string, suggestion map[string]string) {
+	if h.assistant != nil {
+		h.assistant.ProcessTurnInfo(suggester, disprover, revealedCard, suggestion)
+	}
 	if h.name == suggester && revealedCard != "" {
 		C.Info.Printf("You were shown the card: %s\n", revealedCard)
+	} else if revealedCard != "" && disprover != h.name {
+		C.Info.Printf("%s showed the card: %s\n", disprover, revealedCard)
 	}
 }
-func (h *HumanPlayer) ChooseCardToShow(suggestion map[string]string) string {
-	// This would prompt a human player.
-	// For the simulation, we can let it auto-pick.
+func (h *HumanPlayer) ChooseCardToShow(suggester string, suggestion map[string]string) string {
 	var canShow []string
 	for _, card := range suggestion {
 		if _, ok := h.hand[card]; ok {
@@ -669,9 +3031,21 @@ func (h *HumanPlayer) ChooseCardToShow(suggestion map[string]string) string {
 	if len(canShow) == 0 {
 		return ""
 	}
-	return canShow[0] // Just show the first one in simulations
+	card := h.controller.PromptCardToShow(h, suggester, suggestion, canShow)
+	if h.assistant != nil {
+		if err := h.assistant.RecordDisclosure(suggester, card); err != nil {
+			log.Debugf("Co-pilot is not tracking what %s was shown: %v", suggester, err)
+		}
+	}
+	return card
+}
+func (h *HumanPlayer) DisplayNotes() {
+	if h.assistant != nil {
+		h.assistant.DisplayNotes()
+		return
+	}
+	C.Info.Println("Human player notes are managed by the user.")
 }
-func (h *HumanPlayer) DisplayNotes() { C.Info.Println("Human player notes are managed by the user.") }
 
 // --- StringDeque for AI "Patience" ---
 type StringDeque struct {
@@ -698,18 +3072,98 @@ func (d *StringDeque) Contains(s string) bool {
 // --- Main Entry and Game Loop ---
 func main() {
 	logLevel := flag.String("loglevel", "info", "Set logging level (debug, info, warn, error)")
+	openHands := flag.Bool("openhands", false, "Teaching mode: announce every shown card to all players")
+	quiet := flag.Bool("quiet", false, "Only narrate simulation milestones")
+	assist := flag.Bool("assist", false, "Give human players a co-pilot they can ask for advice on their turn")
+	sessionTTL := flag.Duration("session-ttl", 30*time.Minute, "serve-api: evict sessions idle for this long")
+	configName := flag.String("config", defaultConfigPath, "Config file (JSON, or YAML if it ends in .yaml/.yml), or the name of a built-in preset (classic, quick)")
+	svgPath := flag.String("svg", "", "start: save the winner's final notes grid as an SVG file")
+	flag.BoolVar(&showProbabilities, "probabilities", false, "Show estimated percentages in unknown notes cells")
+	focus := flag.Bool("focus", false, "Let AI players use the Focus strategy, probing the category closest to solved")
+	patience := flag.Int("patience", DefaultPatience, "start, benchmark: how many recent Surgical Strike targets AI players avoid re-targeting")
+	accuseAt := flag.Float64("accuse-at", 0, "start, benchmark: let AI players accuse once their best guess is this likely (e.g. 0.9); 0 means only when certain")
+	teams := flag.String("teams", "", "start: partnerships such as 1+2,3+4 (suspect names or numbers); teammates see each other's shown cards and win together")
+	seating := flag.String("seating", "", "start: comma-separated suspects (names or numbers) in turn order, instead of a random table")
+	mrv := flag.Bool("mrv", false, "Let the Exploit strategy probe the unsolved category with the fewest candidates left")
+	infoGain := flag.Bool("infogain", false, "Let AI players explore with the Information Gain strategy, favoring cards closest to being pinned down")
+	bluff := flag.Bool("bluff", false, "Let AI players bluff now and then, naming one of their own cards to mislead the table")
+	flag.BoolVar(&revealPrivateCards, "reveal-private", false, "start: let the narration name the card each suggester is shown in private (players still see only their own)")
+	flag.BoolVar(&asciiOnly, "ascii", false, "Draw charts and tables with plain ASCII characters")
+	noColor := flag.Bool("no-color", os.Getenv("NO_COLOR") != "", "Plain text output: no colors, and ASCII charts and tables (default if NO_COLOR is set)")
+	seed := flag.Int64("seed", 0, "Seed the random deal, seating and AI choices, to replay a run; 0 picks one from the clock and logs it")
+	rngTrace := flag.String("rngtrace", "", "Log every random draw, numbered, to this file (for reproducibility debugging)")
+	deals := flag.Int("deals", 1, "start: play this many deals in a row with the same seating")
+	board := flag.Bool("board", false, "start: board mode, where each suggestion must name the room the suggester's token has moved to")
+	turnDelay := flag.Duration("turn-delay", 100*time.Millisecond, "start: pause this long after each AI turn so the narration can be followed; 0 for none")
+	stalemateRounds := flag.Int("stalemate-rounds", 2, "start: end an all-AI game early once no player has learned anything for this many full rounds; 0 never does, and games with humans never end this way")
+	turnLimit := flag.Int("turn-limit", defaultTurnLimit, "start, benchmark: give up on a game after this many turns")
+	format := flag.String("format", "text", "start: narrate as colored text, or as newline-delimited JSON events (json)")
+	showName := flag.String("show", "random", "start, benchmark: how AI players pick a card to show (random, minimal to re-show cards the suggester has seen, or safe to prefer solved categories)")
+	maxSessions := flag.Int("max-sessions", 100, "serve-api: maximum number of concurrent sessions")
 	flag.Parse()
 	level, err := logrus.ParseLevel(*logLevel)
 	if err != nil {
 		level = logrus.InfoLevel
 	}
 	log.SetLevel(level)
-	log.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true, ForceColors: true})
-
-	if err := loadConfig("default_config.json"); err != nil {
-		log.Fatalf("Failed to load default_config.json: %v", err)
+	aiOpts := AIOptions{Strategies: slices.Clone(DefaultStrategies)}
+	if *focus {
+		aiOpts.Strategies = slices.Insert(aiOpts.Strategies, len(aiOpts.Strategies)-1, StrategyFocus)
+	}
+	if *accuseAt != 0 {
+		if *accuseAt < 0 || *accuseAt > 1 {
+			log.Fatalf("-accuse-at must be between 0 and 1")
+		}
+		aiOpts.Accusation = NewAccusationPolicy(*accuseAt)
+	}
+	aiOpts.ExploitMRV = *mrv
+	if *patience < 0 {
+		log.Fatalf("-patience must not be negative")
+	}
+	aiOpts.Patience = patience
+	if *infoGain {
+		// It takes Explore's place; Explore stays the fallback of last resort.
+		aiOpts.Strategies = slices.Replace(aiOpts.Strategies, len(aiOpts.Strategies)-1, len(aiOpts.Strategies), StrategyInfoGain)
+	}
+	if *bluff {
+		// Bluff goes just before the Explore fallback it is built on.
+		aiOpts.Strategies = slices.Insert(aiOpts.Strategies, len(aiOpts.Strategies)-1, StrategyBluff)
+	}
+	if aiOpts.Show, err = ShowStrategyByName(*showName); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if *noColor {
+		color.NoColor = true
+		asciiOnly = true
+	}
+	log.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true, ForceColors: !*noColor, DisableColors: *noColor})
+
+	if err := selectConfig(*configName); err != nil {
+		switch {
+		case errors.Is(err, ErrConfigNotFound):
+			log.Fatalf("%v. Check the path, or pick a preset (%s).", err, strings.Join(PresetNames(), ", "))
+		case errors.Is(err, ErrConfigParse):
+			log.Fatalf("%v. Check the file for syntax errors.", err)
+		case errors.Is(err, ErrConfigInvalid):
+			log.Fatalf("%v. Fix the card lists in the config.", err)
+		default:
+			log.Fatalf("Failed to load config %s: %v", *configName, err)
+		}
+	}
+	config.AI = aiOpts
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+		log.Infof("Random seed: %d (pass -seed %d to replay this run)", *seed, *seed)
+	}
+	defaultRng = newLockedRng(*seed)
+	if *rngTrace != "" {
+		f, err := os.Create(*rngTrace)
+		if err != nil {
+			log.Fatalf("Could not create RNG trace: %v", err)
+		}
+		defer f.Close()
+		defaultRng = NewTracingRng(defaultRng, f)
 	}
-	rand.Seed(time.Now().UnixNano())
 
 	args := flag.Args()
 	if len(args) < 1 {
@@ -722,257 +3176,135 @@ func main() {
 	line.SetCtrlCAborts(true)
 
 	if args[0] == "detective" {
-		runDetectiveMode(line)
+		newDetectiveSession(line, C).run()
 	} else if args[0] == "start" && len(args) == 3 {
 		numHumans, _ := strconv.Atoi(args[1])
 		numAI, _ := strconv.Atoi(args[2])
-		C.Header.Println("--- Running Fast Simulation ---")
-		game := NewGame(config, numHumans, numAI)
-		game.Deal()
-		runSimulationLoop(game)
-	} else {
-		printUsage()
-	}
-}
-
-func runDetectiveMode(line *liner.State) {
-	C.Info.Println("\n--- Starting Detective Mode Co-Pilot ---")
-
-	// 1. Setup Wizard
-	numPlayers := promptForInt(line, "How many players are in the real game? (2-6): ", 2, 6)
-	var playerNames []string
-	for i := 0; i < numPlayers; i++ {
-		name := promptForString(line, fmt.Sprintf("Enter name for Player %d: ", i+1))
-		playerNames = append(playerNames, name)
-	}
-	myPlayerName := promptForSelection(line, "Which player are you?", playerNames)
-
-	C.Info.Println("\nSelect the cards in your hand. Type 'done' when finished.")
-	myHand := promptForCards(line, true, 0) // 0 means no exact count
-
-	// 2. Create the AI Brain
-	brain := NewAdvancedAIBrain()
-	brain.Setup(config, playerNames, myPlayerName)
-	brain.ReceiveHand(myHand)
-
-	C.Info.Println("\nDetective Mode is active! Your co-pilot is ready.")
-	brain.DisplayNotes()
-
-	printDetectiveHelp()
-
-	// 3. Main Command Loop
-	for {
-		// Use a single, clear prompt. The help text is available via the 'help' command.
-		input, err := line.Prompt("(detective) ")
-		fmt.Printf("%s\n", input)
+		game, err := NewGame(config, numHumans, numAI)
 		if err != nil {
-			if err == liner.ErrPromptAborted || err == io.EOF {
-				C.Info.Println("Goodbye!")
+			C.Warn.Printf("Cannot start: %v. Pick between %d and %d players in total.\n", err, minPlayers, config.MaxPlayers())
+			return
+		}
+		if *format != "json" {
+			C.Header.Println("--- Running Fast Simulation ---")
+		}
+		game.OpenHands = *openHands
+		game.WithTurnLimit(*turnLimit).WithTurnDelay(*turnDelay).WithStalemateDetection(*stalemateRounds)
+		if *board {
+			game.WithBoardMode()
+		}
+		if *seating != "" {
+			var names []string
+			for _, name := range strings.Split(*seating, ",") {
+				card := lookupCard(name)
+				if config.CardToType[card] != "suspects" {
+					card = strings.TrimSpace(name) // Let SetSeating report it.
+				}
+				names = append(names, card)
+			}
+			if err := game.SetSeating(names); err != nil {
+				C.Warn.Printf("Cannot use that seating: %v\n", err)
 				return
 			}
-			log.Fatalf("Error reading line: %v", err)
-		}
-
-		input = strings.TrimSpace(input)
-		if input == "" {
-			continue
 		}
-
-		line.AppendHistory(input)
-		parts := strings.Fields(input)
-		cmd := strings.ToLower(parts[0])
-		args := parts[1:]
-
-		switch cmd {
-		case "log", "l":
-			handleLogCommand(line, brain)
-		case "reveal", "r":
-			handleRevealCommand(line, brain)
-		case "suggest", "s":
-			handleSuggestCommand(brain)
-		case "notes", "n":
-			brain.DisplayNotes()
-		case "hand":
-			handleHandCommand(brain)
-		case "help", "h":
-			handleHelpCommand(args)
-		case "quit", "q":
-			C.Info.Println("Exiting detective mode.")
-			return
-		default:
-			C.Warn.Printf("Unknown command '%s'. Type 'help' for a list of commands.\n", cmd)
+		if *teams != "" {
+			if err := game.SetTeams(parseTeams(*teams)); err != nil {
+				C.Warn.Printf("Cannot use those teams: %v\n", err)
+				return
+			}
 		}
-	}
-}
-
-func handleHelpCommand(args []string) {
-	if len(args) == 0 {
-		// General help
-		C.Header.Println("\n--- Cluedo Toolbox Help ---")
-		fmt.Println("This is the detective co-pilot mode. Log events from your real-life game, and the AI will track everything for you.")
-		fmt.Println("\nAvailable commands:")
-
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.AppendRow(table.Row{"Command", "Alias", "Description"})
-		t.AppendSeparator()
-		t.AppendRows([]table.Row{
-			{"log", "l", "Log a full game turn (suggestion and result)."},
-			{"reveal", "r", "Log a single card revealed by a player."},
-			{"suggest", "s", "Ask the AI co-pilot for a strategic suggestion."},
-			{"notes", "n", "Display the AI's current detective notes grid."},
-			{"hand", "ha", "Display the cards currently in your hand."},
-			{"quit", "q", "Exit detective mode."},
-		})
-		t.SetStyle(table.StyleLight)
-		t.Render()
-
-		fmt.Println("\nType 'help <command>' for more details on a specific command (e.g., 'help log').")
-		return
-	}
-
-	// Specific help for a command
-	command := strings.ToLower(args[0])
-	C.Header.Printf("\n--- Help for: %s ---\n", command)
-	switch command {
-	case "log", "l":
-		fmt.Println("Records one full turn of a real game into the notebook.")
-		C.Prompt.Println("\nUsage:")
-		fmt.Println("  log")
-		C.Prompt.Println("\nDetails:")
-		fmt.Println("  You will be interactively prompted for:")
-		fmt.Println("  1. The Suggester: The player making the suggestion.")
-		fmt.Println("  2. The 3 Cards: The suspect, weapon, and room suggested.")
-		fmt.Println("  3. The Disprover: The player who showed a card. Select 'No One' if applicable.")
-		fmt.Println("  4. The Revealed Card (optional): If you were the suggester, you will be asked which card you were shown.")
-		fmt.Println("\nCards can be entered by their full name or by their ID number from the 'notes' table.")
-
-	case "reveal", "r":
-		fmt.Println("Records that a player revealed a specific card outside of a normal suggestion.")
-		C.Prompt.Println("\nUsage:")
-		fmt.Println("  reveal")
-		C.Prompt.Println("\nDetails:")
-		fmt.Println("  Useful for game variants with Intrigue Cards or house rules.")
-		fmt.Println("  You will be prompted for the player and the card they revealed.")
-
-	case "suggest", "s":
-		fmt.Println("Asks the AI co-pilot for a strategically valuable suggestion for your turn.")
-		C.Prompt.Println("\nUsage:")
-		fmt.Println("  suggest")
-		C.Prompt.Println("\nDetails:")
-		fmt.Println("  The AI will analyze its current knowledge to propose a suggestion that either:")
-		fmt.Println("  - Exploits known information to confirm the final piece of the solution.")
-		fmt.Println("  - Performs a 'Surgical Strike' to solve an outstanding mystery.")
-		fmt.Println("  - Explores to gather new information if no other strategy is viable.")
-
-	case "notes", "n":
-		fmt.Println("Displays the AI's current detective notes grid.")
-		C.Prompt.Println("\nUsage:")
-		fmt.Println("  notes")
-		C.Prompt.Println("\nDetails:")
-		fmt.Println("  This shows what the AI knows about every card, player, and the solution.")
-		fmt.Println("  (✔ = Yes, ✖ = No, ? = Maybe)")
-
-	case "hand", "ha":
-		fmt.Println("Displays the cards you entered as being in your hand at the start.")
-		C.Prompt.Println("\nUsage:")
-		fmt.Println("  hand")
-
-	case "quit", "q":
-		fmt.Println("Exits detective mode and returns to the main application menu.")
-		C.Prompt.Println("\nUsage:")
-		fmt.Println("  quit")
-
-	default:
-		C.Warn.Printf("Unknown command '%s'. Type 'help' for a list of commands.\n", command)
-	}
-}
-
-func handleHandCommand(brain Player) {
-	C.Header.Println("\n--- Your Hand ---")
-	// We need a way to get the hand from the player
-	// Let's add a Hand() method to the Player interface
-	// For now, we'll cast it.
-	if ai, ok := brain.(*AdvancedAIBrain); ok {
-		var cards []string
-		for card := range ai.hand {
-			cards = append(cards, card)
+		for _, p := range game.Players {
+			if h, ok := p.(*HumanPlayer); ok {
+				h.SetController(NewLinerController(line))
+				if *assist {
+					h.EnableAssist()
+				}
+			}
 		}
-		sort.Strings(cards)
-		for _, card := range cards {
-			C.Info.Println(" - " + colorizeCard(card))
+		if err := game.Deal(); err != nil {
+			log.Fatalf("%v", err)
 		}
-	}
-}
-
-func handleLogCommand(line *liner.State, brain Player) {
-	C.Info.Println("\n--- Log a Game Turn ---")
-
-	// --- THE FIX: Use promptForSelection for player names ---
-	playerNames := brain.(*AdvancedAIBrain).players
-	suggester := promptForSelection(line, "Who made the suggestion?", playerNames)
-
-	C.Info.Println("What 3 cards were suggested? (Use numbers or names)")
-	// The promptForCards helper is only for cards.
-	suggestionCards := promptForCards(line, false, 3) // Ask for exactly 3 cards
-	if len(suggestionCards) != 3 {
-		C.Warn.Println("Error: A suggestion must have exactly 3 cards.")
-		return
-	}
-	suggestion := make(map[string]string)
-	for _, card := range suggestionCards {
-		suggestion[config.CardToType[card]] = card
-	}
-
-	disproverOptions := append(playerNames, "No One")
-	disprover := promptForSelection(line, "Who disproved the suggestion?", disproverOptions)
-
-	var revealedCard string
-	if disprover != "No One" && suggester == brain.Name() {
-		C.Info.Println("What card were you shown? (Use numbers or names)")
-		// Use promptForCards to get a single card.
-		revealedCards := promptForCards(line, true, 1)
-		if len(revealedCards) > 0 {
-			revealedCard = revealedCards[0]
+		for i := 0; i < *deals; i++ {
+			if i > 0 {
+				if *format != "json" {
+					C.Header.Printf("\n--- Redealing (deal %d of %d) ---\n", i+1, *deals)
+				}
+				if err := game.Redeal(); err != nil {
+					log.Fatalf("%v", err)
+				}
+			}
+			var winner string
+			if *format == "json" {
+				r := NewJSONStreamRenderer(os.Stdout)
+				r.ShowPrivate = revealPrivateCards
+				winner, err = streamSimulation(game, r)
+			} else {
+				winner, err = runSimulationLoop(game, *quiet)
+			}
+			if err != nil {
+				// A player left; later deals would have nobody to play them.
+				if !errors.Is(err, ErrUserQuit) {
+					C.Warn.Printf("Could not continue: %v\n", err)
+				}
+				return
+			}
+			if *svgPath != "" {
+				saveSimulationSVG(game, winner, *svgPath)
+			}
 		}
-	} else if disprover == "No One" {
-		disprover = ""
-	}
-
-	brain.ProcessTurnInfo(suggester, disprover, revealedCard, suggestion)
-	C.Info.Println("Turn logged. Here are your updated notes:")
-	brain.DisplayNotes()
-}
-
-func handleRevealCommand(line *liner.State, brain Player) {
-	C.Info.Println("\n--- Log a Revealed Card ---")
-	player := promptForSelection(line, "Which player revealed a card?", brain.(*AdvancedAIBrain).players)
-
-	C.Info.Println("Which card did they reveal? (Use number or name)")
-	revealedCards := promptForCards(line, true, 1)
-	if len(revealedCards) == 0 {
-		return // User cancelled
-	}
-	card := revealedCards[0]
-
-	// We can use ProcessTurnInfo with a special suggester to log this fact.
-	// This will call _markCardLocation correctly.
-	brain.ProcessTurnInfo("Game Event", player, card, nil)
-	C.Info.Println("Revealed card logged.")
-	brain.DisplayNotes()
-}
-
-func handleSuggestCommand(brain Player) {
-	C.Header.Println("\n--- AI Co-Pilot Suggestion ---")
-	suggestion := brain.MakeSuggestion()
-	var parts []string
-	for _, card := range suggestion {
-		parts = append(parts, colorizeCard(card))
+	} else if args[0] == "benchmark" && len(args) == 3 {
+		numAI, _ := strconv.Atoi(args[1])
+		numGames, _ := strconv.Atoi(args[2])
+		runBenchmark(numAI, numGames, *turnLimit, *seed)
+	} else if args[0] == "bench" && len(args) == 3 {
+		numAI, _ := strconv.Atoi(args[1])
+		numGames, _ := strconv.Atoi(args[2])
+		runBench(numAI, numGames, *turnLimit, *seed)
+	} else if args[0] == "verify-scenario" && len(args) == 2 {
+		runVerifyScenario(args[1])
+	} else if args[0] == "earliest-solve" && len(args) == 2 {
+		runEarliestSolve(args[1])
+	} else if args[0] == "export-scenario" && (len(args) == 2 || len(args) == 3) {
+		numAI := 4
+		if len(args) == 3 {
+			numAI, _ = strconv.Atoi(args[2])
+		}
+		if err := runExportScenario(args[1], numAI); err != nil {
+			C.Warn.Printf("Could not export scenario: %v\n", err)
+		}
+	} else if args[0] == "generate-log" && (len(args) == 3 || len(args) == 4) {
+		seat, _ := strconv.Atoi(args[1])
+		numAI := 4
+		if len(args) == 4 {
+			numAI, _ = strconv.Atoi(args[3])
+		}
+		if err := runGenerateLog(seat, args[2], numAI, *openHands); err != nil {
+			C.Warn.Printf("Could not generate log: %v\n", err)
+		}
+	} else if args[0] == "export-challenge" && len(args) == 5 {
+		seat, _ := strconv.Atoi(args[2])
+		fromTurn, _ := strconv.Atoi(args[3])
+		if err := runExportChallenge(args[1], seat, fromTurn, args[4]); err != nil {
+			C.Warn.Printf("Could not export challenge: %v\n", err)
+		}
+	} else if args[0] == "play-challenge" && len(args) == 2 {
+		runPlayChallenge(line, args[1])
+	} else if args[0] == "serve-api" && (len(args) == 1 || len(args) == 2) {
+		addr := ":8080"
+		if len(args) == 2 {
+			addr = args[1]
+		}
+		runServeAPI(addr, *sessionTTL, *maxSessions)
+	} else {
+		printUsage()
 	}
-	C.Info.Printf("The AI suggests you propose: %s\n", strings.Join(parts, ", "))
 }
 
-func runSimulationLoop(g *Game) {
+// runSimulationLoop plays and narrates a game. In quiet mode only milestones
+// are printed: strategy changes, solved mysteries and the accusation. It
+// returns the name of the player who accused, or "" if nobody did. If a
+// human leaves, the game ends at once with Game.Err, e.g. ErrUserQuit.
+func runSimulationLoop(g *Game, quiet bool) (string, error) {
 	C.Header.Println("--- Starting Game ---")
 
 	// --- NEW: Store initial brain states ---
@@ -983,56 +3315,121 @@ func runSimulationLoop(g *Game) {
 		}
 	}
 
-	displayPlayer := g.Players[0] // Default display
 	for _, p := range g.Players {
-		if !p.IsHuman() {
-			displayPlayer = p
-			break
+		if ai, ok := p.(*AdvancedAIBrain); ok {
+			name := ai.Name()
+			ai.OnSolutionFound = func(solution map[string]string) {
+				C.Header.Printf("[Turn %d] %s has worked out the whole solution!\n", g.turn+1, colorizeCard(name))
+			}
 		}
 	}
-	displayPlayer.DisplayNotes() // Show initial state without comparison
+	if quiet {
+		// The brains narrate every deduction at info level; report milestones ourselves.
+		if log.GetLevel() > logrus.WarnLevel {
+			log.SetLevel(logrus.WarnLevel)
+		}
+		for _, p := range g.Players {
+			if ai, ok := p.(*AdvancedAIBrain); ok {
+				name := ai.Name()
+				ai.OnMysterySolved = func(disprover, card string) {
+					C.Info.Printf("[Turn %d] %s solved a mystery: %s has %s.\n", g.turn+1, colorizeCard(name), colorizeCard(disprover), card)
+				}
+			}
+		}
+	} else {
+		displayPlayer := g.Players[0] // Default display
+		for _, p := range g.Players {
+			if !p.IsHuman() {
+				displayPlayer = p
+				break
+			}
+		}
+		displayPlayer.DisplayNotes() // Show initial state without comparison
+	}
 
 	winner := ""
+	strategies := make(map[string]string)
 
-	for g.turn < 50 {
-		currentPlayer := g.Players[g.turn%len(g.Players)]
-		C.Header.Printf("\n--- Turn %d: %s ---\n", g.turn+1, colorizeCard(currentPlayer.Name()))
+	for g.turn < g.TurnLimit() && !g.Stalemate() {
+		if !quiet {
+			C.Header.Printf("\n--- Turn %d: %s ---\n", g.turn+1, colorizeCard(g.CurrentPlayer().Name()))
+		}
+		turn := g.turn + 1
+		out := g.PlayTurn()
+		if err := g.Err(); err != nil {
+			C.Header.Printf("\n--- GAME ABANDONED (%s left) ---\n", colorizeCard(out.Player.Name()))
+			return "", err
+		}
+		currentPlayer := out.Player
 
-		if accusation := currentPlayer.ShouldAccuse(); accusation != nil {
-			winner = currentPlayer.Name()
-			isCorrect := true
-			for cat, card := range accusation {
-				if g.Solution[cat] != card {
-					isCorrect = false
-					break
+		if out.Accusation != nil {
+			if quiet {
+				C.Header.Printf("[Turn %d] ", turn)
+			}
+			C.Info.Printf("%s accuses! The solution is %v. This is %t\n", colorizeCard(currentPlayer.Name()), values(out.Accusation), out.Correct)
+			if out.Eliminated {
+				C.No.Printf("%s is eliminated, but will still show cards.\n", colorizeCard(currentPlayer.Name()))
+			} else if out.WinningDeduction != "" {
+				C.Info.Printf("The final piece came from: %s\n", out.WinningDeduction)
+			}
+			if g.Finished() {
+				winner = g.Winner()
+				if out.Eliminated {
+					C.Info.Printf("%s is the last player standing and wins.\n", colorizeCard(winner))
 				}
+				break
 			}
-			C.Info.Printf("%s accuses! The solution is %v. This is %t\n", colorizeCard(currentPlayer.Name()), values(accusation), isCorrect)
-			break
+			continue
+		}
+
+		if quiet {
+			if prev, ok := strategies[currentPlayer.Name()]; out.Strategy != "" && out.Strategy != prev {
+				if ok {
+					C.Info.Printf("[Turn %d] %s switches strategy: %s -> %s\n", turn, colorizeCard(currentPlayer.Name()), prev, out.Strategy)
+				}
+				strategies[currentPlayer.Name()] = out.Strategy
+			}
+			continue
 		}
 
-		suggestion := currentPlayer.MakeSuggestion()
-		C.Info.Printf("%s suggests: %v\n", colorizeCard(currentPlayer.Name()), values(suggestion))
-		disproverName, revealedCard := g.HandleSuggestion(currentPlayer, suggestion)
+		if out.Suggestion == nil {
+			C.Warn.Printf("%s made no valid suggestion this turn.\n", colorizeCard(currentPlayer.Name()))
+			continue
+		}
+		if out.Room != "" {
+			C.Info.Printf("%s moves to the %s.\n", colorizeCard(currentPlayer.Name()), out.Room)
+		}
+		C.Info.Printf("%s suggests: %v\n", colorizeCard(currentPlayer.Name()), values(out.Suggestion))
 
-		if disproverName != "" {
-			C.Info.Printf("-> %s shows a card to %s.\n", colorizeCard(disproverName), colorizeCard(currentPlayer.Name()))
-			log.Debugf(" (The card was '%s')", revealedCard)
+		if out.Disprover != "" && g.OpenHands {
+			C.Info.Printf("-> %s shows %s to everyone.\n", colorizeCard(out.Disprover), out.RevealedCard)
+		} else if out.Disprover != "" {
+			C.Info.Printf("-> %s shows a card to %s.\n", colorizeCard(out.Disprover), colorizeCard(currentPlayer.Name()))
+			if revealPrivateCards {
+				C.Info.Printf("   (privately, to %s: %s)\n", colorizeCard(currentPlayer.Name()), out.RevealedCard)
+			} else {
+				log.Debugf(" (The card was '%s')", out.RevealedCard)
+			}
 		} else {
 			C.Info.Println("-> No player could show a card.")
 		}
 
-		for _, p := range g.Players {
-			p.ProcessTurnInfo(currentPlayer.Name(), disproverName, revealedCard, suggestion)
+		if !currentPlayer.IsHuman() && g.turnDelay > 0 {
+			time.Sleep(g.turnDelay)
 		}
+	}
 
-		g.turn++
-		if !currentPlayer.IsHuman() {
-			time.Sleep(100 * time.Millisecond)
+	if winner == "" && g.Stalemate() {
+		C.Header.Printf("\n--- STALEMATE (nobody learned anything in %d rounds) ---\n", g.stalemateRounds)
+	} else if winner == "" {
+		// Nobody accused: a stalemate, not a game over.
+		C.Header.Printf("\n--- TURN LIMIT REACHED (%d turns) ---\n", g.TurnLimit())
+	} else {
+		C.Header.Println("\n--- GAME OVER ---")
+		if team := g.WinningTeam(); len(team) > 1 {
+			C.Info.Printf("Team %s wins together.\n", strings.Join(team, " + "))
 		}
 	}
-
-	C.Header.Println("\n--- GAME OVER ---")
 	C.Info.Printf("Solution was: %v\n", g.Solution)
 	// --- NEW: Display the final comparison table ---
 	if winner != "" {
@@ -1050,14 +3447,32 @@ func runSimulationLoop(g *Game) {
 			winningPlayer.DisplayNotes()
 		}
 	}
+	return winner, nil
+}
+
+// saveSimulationSVG writes the winner's notes, or the first AI's if the winner
+// was human or nobody accused.
+func saveSimulationSVG(g *Game, winner, path string) {
+	var brain *AdvancedAIBrain
+	for _, p := range g.Players {
+		if ai, ok := p.(*AdvancedAIBrain); ok && (brain == nil || ai.Name() == winner) {
+			brain = ai
+		}
+	}
+	if brain == nil {
+		C.Warn.Println("No AI notes to save as SVG.")
+		return
+	}
+	if err := writeNotesSVG(path, brain); err != nil {
+		C.Warn.Printf("Could not save SVG: %v\n", err)
+		return
+	}
+	C.Info.Printf("Saved %s's notes to %s.\n", brain.Name(), path)
 }
 
 // --- UI and Helper Functions ---
 func printUsage() {
-	fmt.Println("\nUsage:\n  go run . detective\n  go run . start <num_humans> <num_ai> [-loglevel debug]")
-}
-func printDetectiveHelp() {
-	fmt.Println(C.Prompt.Sprint("\n(log, reveal, suggest, notes, quit)"))
+	fmt.Println("\nUsage (every command accepts -config <file|classic|quick>, -seed N, -no-color and -rngtrace <file>):\n  go run . [-probabilities] [-ascii] detective\n  go run . [-loglevel debug] [-focus] [-infogain] [-mrv] [-bluff] [-accuse-at P] [-patience N] [-openhands] [-board] [-assist] [-quiet] [-deals N] [-svg file] [-turn-limit N] [-stalemate-rounds N] [-turn-delay D] [-format json] [-show random|minimal|safe] [-seating a,b,...] [-teams a+b,c+d] [-reveal-private] start <num_humans> <num_ai>\n  go run . [-turn-limit N] [-show random|minimal|safe] [-focus] [-infogain] [-mrv] [-bluff] [-accuse-at P] [-patience N] benchmark <num_ai> <num_games>\n  go run . [-turn-limit N] [-show random|minimal|safe] [-focus] [-infogain] [-mrv] [-bluff] [-accuse-at P] [-patience N] bench <num_ai> <num_games>\n  go run . generate-log <seat> <outfile> [num_ai]\n  go run . export-scenario <outfile> [num_ai]\n  go run . verify-scenario <file>\n  go run . earliest-solve <file>\n  go run . export-challenge <scenario> <seat> <from_turn> <outfile>\n  go run . play-challenge <file>\n  go run . [-session-ttl 30m] [-max-sessions 100] serve-api [addr]")
 }
 
 // --- UI and Helper Functions ---
@@ -1073,7 +3488,30 @@ func (ai *AdvancedAIBrain) deepCopyKnowledge() map[string]map[string]CardStatus
 	return newKnowledge
 }
 
-func promptForCards(line *liner.State, requireAtLeastOne bool, exactCount int) []string {
+// ErrUserQuit is returned by the prompt helpers when the user ends input
+// with Ctrl-D, or presses Ctrl-C anywhere but in a card prompt, where that
+// only cancels the entry.
+var ErrUserQuit = errors.New("user quit")
+
+// readPrompt shows prompt and reads one line, turning the end of input into
+// ErrUserQuit. liner.ErrPromptAborted is passed through for callers that
+// treat Ctrl-C differently.
+func readPrompt(line InputSource, prompt string) (string, error) {
+	// Print the colored part first, then prompt with an empty string.
+	C.Prompt.Print(prompt)
+	input, err := line.Prompt("")
+	switch {
+	case err == io.EOF:
+		return "", ErrUserQuit
+	case err != nil && err != liner.ErrPromptAborted:
+		return "", fmt.Errorf("reading input: %w", err)
+	}
+	return input, err
+}
+
+// promptForCards reads card names or numbers. Ctrl-C cancels the entry and
+// returns no cards.
+func promptForCards(line InputSource, requireAtLeastOne bool, exactCount int) ([]string, error) {
 	var cards []string
 	cardSet := make(map[string]struct{})
 
@@ -1110,16 +3548,12 @@ func promptForCards(line *liner.State, requireAtLeastOne bool, exactCount int) [
 		}
 		prompt += ": "
 
-		// --- THE FIX ---
-		// Print the colored part first, then prompt with an empty string.
-		C.Prompt.Print(prompt)
-		input, err := line.Prompt("")
-		if err != nil {
-			if err == liner.ErrPromptAborted || err == io.EOF {
-				C.Info.Println("Aborting.")
-				return []string{}
-			}
-			log.Fatalf("Error reading line: %v", err)
+		input, err := readPrompt(line, prompt)
+		if err == liner.ErrPromptAborted {
+			C.Info.Println("Aborting.")
+			return []string{}, nil
+		} else if err != nil {
+			return nil, err
 		}
 		input = strings.TrimSpace(input)
 
@@ -1131,18 +3565,7 @@ func promptForCards(line *liner.State, requireAtLeastOne bool, exactCount int) [
 			break
 		}
 
-		var foundCard string
-		if num, err := strconv.Atoi(input); err == nil && num >= 1 && num <= len(config.AllCards) {
-			foundCard = config.AllCards[num-1]
-		} else {
-			for _, card := range config.AllCards {
-				if strings.EqualFold(card, input) {
-					foundCard = card
-					break
-				}
-			}
-		}
-
+		foundCard := lookupCard(input)
 		if foundCard == "" {
 			C.Warn.Printf("Error: Card '%s' not found.\n", input)
 		} else if _, exists := cardSet[foundCard]; exists {
@@ -1154,19 +3577,111 @@ func promptForCards(line *liner.State, requireAtLeastOne bool, exactCount int) [
 			line.AppendHistory(input) // Append valid history
 		}
 	}
-	return cards
+	return cards, nil
+}
+
+func (ai *AdvancedAIBrain) DisplayNotes() { ai.RenderNotes(C, nil) }
+
+// Notes returns the notes grid as plain text, one card per row and one
+// column per player plus the solution: Y for yes, N for no, ? for unknown.
+// It uses no colors or table library, for callers without a terminal.
+func (ai *AdvancedAIBrain) Notes() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	locations := append(append([]string{}, ai.players...), "solution")
+	fmt.Fprintf(w, "Card\tCategory\t%s\n", strings.Join(locations, "\t"))
+	marks := map[CardStatus]string{StatusYes: "Y", StatusNo: "N", StatusMaybe: "?"}
+	for _, card := range ai.config.AllCards {
+		row := []string{card, ai.config.CardToType[card]}
+		for _, loc := range locations {
+			row = append(row, marks[ai.knowledge[card][loc]])
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+	return b.String()
+}
+
+// NoteCell identifies a single cell of the notes grid.
+type NoteCell struct {
+	Card, Location string
+}
+
+// changedCells returns the cells whose status differs between two knowledge snapshots.
+func changedCells(before, after map[string]map[string]CardStatus) map[NoteCell]bool {
+	changed := make(map[NoteCell]bool)
+	for card, locations := range after {
+		for loc, status := range locations {
+			if before[card][loc] != status {
+				changed[NoteCell{card, loc}] = true
+			}
+		}
+	}
+	return changed
+}
+
+// statusSymbol renders a knowledge cell. Highlighted cells are drawn bold and
+// underlined, or suffixed with '*' when colors are disabled.
+func statusSymbol(theme *Theme, status CardStatus, highlight bool) string {
+	c, glyph := theme.Maybe, "?"
+	switch status {
+	case StatusYes:
+		c, glyph = theme.Yes, "✔"
+	case StatusNo:
+		c, glyph = theme.No, "✖"
+	}
+	if !highlight {
+		return c.Sprint(glyph)
+	}
+	if color.NoColor {
+		return glyph + "*"
+	}
+	return color.New(color.Bold, color.Underline).Sprint(c.Sprint(glyph))
+}
+
+// Contradictions returns the cards whose rows cannot be right, mapped to
+// "impossible" (no place left for the card) or "in two places". Correct
+// entries never produce one, so any result points at a logging mistake.
+func (ai *AdvancedAIBrain) Contradictions() map[string]string {
+	bad := make(map[string]string)
+	for _, card := range ai.config.AllCards {
+		yes, open := 0, 0
+		for _, loc := range append(append([]string{}, ai.players...), "solution") {
+			switch ai.knowledge[card][loc] {
+			case StatusYes:
+				yes++
+			case StatusMaybe:
+				open++
+			}
+		}
+		switch {
+		case yes > 1:
+			bad[card] = "in two places"
+		case yes == 0 && open == 0:
+			bad[card] = "impossible"
+		}
+	}
+	return bad
 }
 
-func (ai *AdvancedAIBrain) DisplayNotes() {
+// RenderNotes prints the notes grid in the given theme, highlighting the given
+// cells (may be nil). Rows listed by Contradictions are flagged in the
+// warning color.
+func (ai *AdvancedAIBrain) RenderNotes(theme *Theme, highlight map[NoteCell]bool) {
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
 	t.SetTitle(fmt.Sprintf("%s's Detective Notes", ai.name))
+	var probs map[string]map[string]float64
+	if showProbabilities {
+		probs = ai.Probabilities()
+	}
+	bad := ai.Contradictions()
 
 	// --- Build Header ---
 	header := table.Row{"ID", "Card", "Type"}
 	// We build the header from the AI's known list of players
 	for _, pName := range ai.players {
-		header = append(header, colorizeCard(pName))
+		header = append(header, theme.Card(pName))
 	}
 	header = append(header, "Solution")
 	t.AppendHeader(header)
@@ -1182,36 +3697,25 @@ func (ai *AdvancedAIBrain) DisplayNotes() {
 		}
 
 		// Start building the row with known, valid data.
-		row := table.Row{cardID + 1, colorizeCard(card), ai.config.CardToType[card]}
-
-		// Look up the knowledge for this card for each player.
-		for _, pName := range ai.players {
-			status := ai.knowledge[card][pName]
-			symbol := C.Maybe.Sprint("?")
-			if status == StatusYes {
-				symbol = C.Yes.Sprint("✔")
-			}
-			if status == StatusNo {
-				symbol = C.No.Sprint("✖")
-			}
-			row = append(row, symbol)
+		name := theme.Card(card)
+		if problem, ok := bad[card]; ok {
+			name = theme.Warn.Sprintf("%s ⚠ %s", card, problem)
 		}
+		row := table.Row{cardID + 1, name, ai.config.CardToType[card]}
 
-		// Look up the solution status for this card.
-		status := ai.knowledge[card]["solution"]
-		symbol := C.Maybe.Sprint("?")
-		if status == StatusYes {
-			symbol = C.Yes.Sprint("✔")
-		}
-		if status == StatusNo {
-			symbol = C.No.Sprint("✖")
+		// Look up the knowledge for this card for each player, then the solution.
+		for _, loc := range append(append([]string{}, ai.players...), "solution") {
+			cell := statusSymbol(theme, ai.knowledge[card][loc], highlight[NoteCell{card, loc}])
+			if probs != nil && ai.knowledge[card][loc] == StatusMaybe {
+				cell += fmt.Sprintf(" %3.0f%%", probs[card][loc]*100)
+			}
+			row = append(row, cell)
 		}
-		row = append(row, symbol)
 
 		t.AppendRow(row)
 	}
 
-	t.SetStyle(table.StyleRounded)
+	t.SetStyle(tableStyle(table.StyleRounded))
 	t.Style().Options.SeparateRows = false // We use AppendSeparator
 	t.Style().Title.Align = text.AlignCenter
 	t.SetColumnConfigs([]table.ColumnConfig{
@@ -1219,32 +3723,156 @@ func (ai *AdvancedAIBrain) DisplayNotes() {
 	})
 
 	t.Render()
+	ai.renderSolutionOdds(theme)
+}
+
+// renderSolutionOdds follows the notes with one line per category naming its
+// remaining solution candidates, likeliest first, or its answer once solved.
+func (ai *AdvancedAIBrain) renderSolutionOdds(theme *Theme) {
+	known := ai._knownSolutionCards()
+	for _, cat := range ai.config.CategoryNames() {
+		if card, ok := known[cat]; ok {
+			theme.Yes.Printf("%s: %s\n", cat, card)
+			continue
+		}
+		dist := ai.SolutionDistribution(cat)
+		var candidates []string
+		for card, p := range dist {
+			if p > 0 {
+				candidates = append(candidates, card)
+			}
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			if dist[candidates[i]] != dist[candidates[j]] {
+				return dist[candidates[i]] > dist[candidates[j]]
+			}
+			return candidates[i] < candidates[j]
+		})
+		var parts []string
+		for _, card := range candidates {
+			parts = append(parts, fmt.Sprintf("%s %.0f%%", theme.Card(card), dist[card]*100))
+		}
+		if len(parts) == 0 {
+			theme.Warn.Printf("%s: no candidate left\n", cat)
+			continue
+		}
+		fmt.Printf("%s: %s\n", cat, strings.Join(parts, ", "))
+	}
 }
 
+// Errors returned while loading a config file. Callers can match them with
+// errors.Is to give tailored advice.
+var (
+	ErrConfigNotFound = errors.New("config file not found")
+	ErrConfigParse    = errors.New("config file could not be parsed")
+	ErrConfigInvalid  = errors.New("invalid config")
+)
+
 func loadConfig(path string) error {
-	data, err := ioutil.ReadFile(path)
+	cfg, err := readConfig(path)
 	if err != nil {
 		return err
 	}
-	if err := json.Unmarshal(data, &config); err != nil {
-		return err
+	config = cfg
+	return nil
+}
+
+// readConfig parses and validates a config file without touching the global config.
+func readConfig(path string) (GameConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return GameConfig{}, fmt.Errorf("%w: %s", ErrConfigNotFound, path)
+		}
+		return GameConfig{}, err
+	}
+	return parseConfig(data, path)
+}
+
+// parseConfig decodes and validates config data; path names it in errors
+// and decides whether it is YAML.
+func parseConfig(data []byte, path string) (GameConfig, error) {
+	var cfg GameConfig
+	unmarshal := json.Unmarshal
+	if isYAMLPath(path) {
+		unmarshal = yaml.Unmarshal
+	}
+	if err := unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%w: %s: %w", ErrConfigParse, path, err)
+	}
+	cfg.buildIndex()
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
 	}
-	config.AllCards = append(config.AllCards, config.Suspects...)
-	config.AllCards = append(config.AllCards, config.Weapons...)
-	config.AllCards = append(config.AllCards, config.Rooms...)
-	config.CardToType = make(map[string]string)
-	for _, card := range config.Suspects {
-		config.CardToType[card] = "suspects"
+	return cfg, nil
+}
+
+// isYAMLPath reports whether a config path should be read as YAML.
+func isYAMLPath(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// buildIndex fills in Categories, AllCards and CardToType from the card lists.
+func (cfg *GameConfig) buildIndex() {
+	cfg.Categories = append([]Category{
+		{Name: "suspects", Cards: cfg.Suspects},
+		{Name: "weapons", Cards: cfg.Weapons},
+		{Name: "rooms", Cards: cfg.Rooms},
+	}, cfg.Extra...)
+	cfg.AllCards = nil
+	cfg.CardToType = make(map[string]string)
+	for _, cat := range cfg.Categories {
+		cfg.AllCards = append(cfg.AllCards, cat.Cards...)
+		for _, card := range cat.Cards {
+			cfg.CardToType[card] = cat.Name
+		}
+	}
+}
+
+// Validate checks that every category has cards and that card names are unique.
+func (cfg GameConfig) Validate() error {
+	if len(cfg.Suspects) < minPlayers {
+		return fmt.Errorf("%w: need at least %d suspects to seat %d players", ErrConfigInvalid, minPlayers, minPlayers)
 	}
-	for _, card := range config.Weapons {
-		config.CardToType[card] = "weapons"
+	names := make(map[string]bool)
+	for _, cat := range cfg.Categories {
+		if cat.Name == "" || names[cat.Name] {
+			return fmt.Errorf("%w: missing or duplicate category name %q", ErrConfigInvalid, cat.Name)
+		}
+		names[cat.Name] = true
+		if len(cat.Cards) == 0 {
+			return fmt.Errorf("%w: no %s listed", ErrConfigInvalid, cat.Name)
+		}
 	}
-	for _, card := range config.Rooms {
-		config.CardToType[card] = "rooms"
+	seen := make(map[string]bool)
+	for _, card := range cfg.AllCards {
+		if strings.TrimSpace(card) == "" {
+			return fmt.Errorf("%w: empty card name", ErrConfigInvalid)
+		}
+		if seen[card] {
+			return fmt.Errorf("%w: duplicate card %q", ErrConfigInvalid, card)
+		}
+		seen[card] = true
 	}
 	return nil
 }
 
+// isCompleteSuggestion reports whether a suggestion names exactly one valid card
+// for each category.
+func isCompleteSuggestion(cfg GameConfig, suggestion map[string]string) bool {
+	if len(suggestion) != len(cfg.Categories) {
+		return false
+	}
+	for _, cat := range cfg.CategoryNames() {
+		card, ok := suggestion[cat]
+		if !ok || card == "" || cfg.CardToType[card] != cat {
+			return false
+		}
+	}
+	return true
+}
+
 func values(m map[string]string) []string {
 	var v []string
 	for _, val := range m {
@@ -1254,6 +3882,12 @@ func values(m map[string]string) []string {
 	return v
 }
 
+func sortedKeys(m map[string]struct{}) []string {
+	k := mapKeys(m)
+	sort.Strings(k)
+	return k
+}
+
 func mapKeys(m map[string]struct{}) []string {
 	var k []string
 	for key := range m {
@@ -1262,17 +3896,28 @@ func mapKeys(m map[string]struct{}) []string {
 	return k
 }
 
-func promptForInt(line *liner.State, prompt string, min, max int) int {
+// lookupCard resolves a card typed as its number in the notes table or its
+// name (case-insensitive). It returns "" if nothing matches.
+func lookupCard(input string) string {
+	input = strings.TrimSpace(input)
+	if num, err := strconv.Atoi(input); err == nil && num >= 1 && num <= len(config.AllCards) {
+		return config.AllCards[num-1]
+	}
+	for _, card := range config.AllCards {
+		if strings.EqualFold(card, input) {
+			return card
+		}
+	}
+	return ""
+}
+
+func promptForInt(line InputSource, prompt string, min, max int) (int, error) {
 	for {
-		// THE FIX: Print the colored part first, then use an uncolored prompt.
-		C.Prompt.Print(prompt)
-		input, err := line.Prompt("")
-		if err != nil {
-			if err == liner.ErrPromptAborted || err == io.EOF {
-				C.Info.Println("Goodbye!")
-				os.Exit(0)
-			}
-			log.Fatalf("Error reading line: %v", err)
+		input, err := readPrompt(line, prompt)
+		if err == liner.ErrPromptAborted {
+			return 0, ErrUserQuit
+		} else if err != nil {
+			return 0, err
 		}
 
 		num, err := strconv.Atoi(strings.TrimSpace(input))
@@ -1281,31 +3926,27 @@ func promptForInt(line *liner.State, prompt string, min, max int) int {
 			continue
 		}
 		line.AppendHistory(input)
-		return num
+		return num, nil
 	}
 }
 
-func promptForString(line *liner.State, prompt string) string {
+func promptForString(line InputSource, prompt string) (string, error) {
 	for {
-		// THE FIX: Print the colored part first, then use an uncolored prompt.
-		C.Prompt.Print(prompt)
-		input, err := line.Prompt("")
-		if err != nil {
-			if err == liner.ErrPromptAborted || err == io.EOF {
-				C.Info.Println("Goodbye!")
-				os.Exit(0)
-			}
-			log.Fatalf("Error reading line: %v", err)
+		input, err := readPrompt(line, prompt)
+		if err == liner.ErrPromptAborted {
+			return "", ErrUserQuit
+		} else if err != nil {
+			return "", err
 		}
 		if strings.TrimSpace(input) == "" {
 			continue
 		}
 		line.AppendHistory(input)
-		return strings.TrimSpace(input)
+		return strings.TrimSpace(input), nil
 	}
 }
 
-func promptForSelection(line *liner.State, prompt string, options []string) string {
+func promptForSelection(line InputSource, prompt string, options []string) (string, error) {
 	for {
 		// Display the prompt and options to the user
 		C.Header.Println(prompt)
@@ -1314,21 +3955,17 @@ func promptForSelection(line *liner.State, prompt string, options []string) stri
 			fmt.Printf(" %2d: %s\n", i+1, colorizeCard(opt))
 		}
 
-		// THE FIX: Print the colored part first, then prompt with an empty string.
-		C.Prompt.Print("Enter number: ")
-		input, err := line.Prompt("")
-		if err != nil {
-			if err == liner.ErrPromptAborted || err == io.EOF {
-				C.Info.Println("Goodbye!")
-				os.Exit(0)
-			}
-			log.Fatalf("Error reading line: %v", err)
+		input, err := readPrompt(line, "Enter number: ")
+		if err == liner.ErrPromptAborted {
+			return "", ErrUserQuit
+		} else if err != nil {
+			return "", err
 		}
 
 		num, err := strconv.Atoi(strings.TrimSpace(input))
 		if err == nil && num >= 1 && num <= len(options) {
 			line.AppendHistory(input)
-			return options[num-1]
+			return options[num-1], nil
 		}
 		C.Warn.Println("Invalid selection.")
 	}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadConfigYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mansion.yaml")
+	data := `# A small house with a fourth category.
+suspects: [Scarlett, "Mustard", 'Peacock']
+weapons:
+  - Rope
+  - Dagger # sharp
+rooms:
+  - Hall
+  - "Dining Room"
+extra_categories:
+  - name: motives
+    cards: [Greed, Revenge]
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := readConfig(path)
+	if err != nil {
+		t.Fatalf("readConfig: %v", err)
+	}
+	if got, want := cfg.CategoryNames(), []string{"suspects", "weapons", "rooms", "motives"}; !slices.Equal(got, want) {
+		t.Errorf("categories = %v, want %v", got, want)
+	}
+	want := []string{"Scarlett", "Mustard", "Peacock", "Rope", "Dagger", "Hall", "Dining Room", "Greed", "Revenge"}
+	if !slices.Equal(cfg.AllCards, want) {
+		t.Errorf("cards = %v, want %v", cfg.AllCards, want)
+	}
+	if got := cfg.CardToType["Revenge"]; got != "motives" {
+		t.Errorf("Revenge is in %q, want motives", got)
+	}
+}
+
+func TestParseConfigRejectsBadYAML(t *testing.T) {
+	for _, tc := range []struct {
+		name, data string
+		want       error
+	}{
+		{"nested sequence", "suspects:\n  - - a\n  - b\nweapons: [c]\nrooms: [d]\n", ErrConfigParse},
+		{"value with a colon", "suspects: a: b\nweapons: [c]\nrooms: [d]\n", ErrConfigParse},
+		{"tab indentation", "suspects:\n\t- a\n", ErrConfigParse},
+		{"empty item", "suspects:\n  -\n  - a\nweapons: [c]\nrooms: [d]\n", ErrConfigInvalid},
+		{"missing rooms", "suspects: [a, b]\nweapons: [c]\n", ErrConfigInvalid},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseConfig([]byte(tc.data), "bad.yml"); !errors.Is(err, tc.want) {
+				t.Errorf("parseConfig = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestMissingConfigIsNotFound(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "nowhere.json")
+	if _, err := readConfig(missing); !errors.Is(err, ErrConfigNotFound) {
+		t.Errorf("readConfig = %v, want %v", err, ErrConfigNotFound)
+	}
+	if err := selectConfig(missing); !errors.Is(err, ErrConfigNotFound) {
+		t.Errorf("selectConfig = %v, want %v", err, ErrConfigNotFound)
+	}
+	if _, err := Preset("no-such-preset"); !errors.Is(err, ErrConfigNotFound) {
+		t.Errorf("Preset = %v, want %v", err, ErrConfigNotFound)
+	}
+}
+
+func TestYAMLParseErrorDoesNotMentionJSON(t *testing.T) {
+	_, err := parseConfig([]byte("suspects: [a\n"), "bad.yaml")
+	if !errors.Is(err, ErrConfigParse) {
+		t.Fatalf("parseConfig = %v, want %v", err, ErrConfigParse)
+	}
+	if strings.Contains(err.Error(), "JSON") {
+		t.Errorf("error for a YAML file mentions JSON: %v", err)
+	}
+}
+
+func TestQuickPresetDealsForThreePlayers(t *testing.T) {
+	cfg, err := Preset("quick")
+	if err != nil {
+		t.Fatalf("Preset(quick): %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("the quick preset does not validate: %v", err)
+	}
+	g, err := NewGameWithRng(*cfg, 0, 3, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Deal(); err != nil {
+		t.Fatalf("Deal: %v", err)
+	}
+
+	// 13 cards less the solution leaves 10: hands of 4, 3 and 3.
+	var sizes []int
+	seen := make(map[string]bool)
+	for _, p := range g.Players {
+		hand := g.Hands[p.Name()]
+		sizes = append(sizes, len(hand))
+		for _, card := range hand {
+			seen[card] = true
+		}
+	}
+	if !slices.Equal(sizes, []int{4, 3, 3}) {
+		t.Errorf("hand sizes %v, want [4 3 3]", sizes)
+	}
+	for _, cat := range cfg.CategoryNames() {
+		card := g.Solution[cat]
+		if cfg.CardToType[card] != cat || seen[card] {
+			t.Errorf("the %s solution %q is not a %s card kept out of every hand", cat, card, cat)
+		}
+		seen[card] = true
+	}
+	if len(seen) != len(cfg.AllCards) {
+		t.Errorf("%d of %d cards dealt", len(seen), len(cfg.AllCards))
+	}
+
+	g.Play(defaultTurnLimit)
+	if !g.Finished() {
+		t.Errorf("a quick game did not finish within %d turns", defaultTurnLimit)
+	}
+}
@@ -0,0 +1,130 @@
+// controller.go
+// Where a human player's decisions come from: the terminal or nowhere.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/peterh/liner"
+)
+
+// Controller makes a human player's decisions. The game asks the player,
+// and the player asks its controller.
+type Controller interface {
+	// PromptSuggestion returns the suggestion for this turn, or nil to pass.
+	// An error such as ErrUserQuit means the player has left the game.
+	PromptSuggestion(h *HumanPlayer) (map[string]string, error)
+	// PromptAccusation returns an accusation, or nil to keep playing. Errors
+	// are as for PromptSuggestion.
+	PromptAccusation(h *HumanPlayer) (map[string]string, error)
+	// PromptCardToShow picks which of canShow, which is never empty, to show
+	// the suggester.
+	PromptCardToShow(h *HumanPlayer, suggester string, suggestion map[string]string, canShow []string) string
+}
+
+// NullController is the headless controller: it never suggests or accuses
+// and shows the first matching card in sorted order.
+type NullController struct{}
+
+func (NullController) PromptSuggestion(h *HumanPlayer) (map[string]string, error) { return nil, nil }
+func (NullController) PromptAccusation(h *HumanPlayer) (map[string]string, error) { return nil, nil }
+func (NullController) PromptCardToShow(h *HumanPlayer, suggester string, suggestion map[string]string, canShow []string) string {
+	sorted := append([]string{}, canShow...)
+	sort.Strings(sorted)
+	return sorted[0]
+}
+
+// LinerController asks the person at the terminal.
+type LinerController struct {
+	line InputSource
+}
+
+func NewLinerController(line InputSource) *LinerController { return &LinerController{line: line} }
+
+func (c *LinerController) PromptSuggestion(h *HumanPlayer) (map[string]string, error) {
+	if h.room != "" {
+		C.Info.Printf("You are in the %s; your suggestion will name it.\n", h.room)
+	}
+	for {
+		input, err := readPrompt(c.line, "Your turn (suggest, advice, notes, pass): ")
+		if err != nil {
+			return nil, quitError(err)
+		}
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "suggest", "s":
+			C.Info.Printf("Which %d cards do you suggest? (Use numbers or names)\n", len(h.cfg.Categories))
+			suggestion, err := c.promptCombination(h)
+			if err != nil || suggestion != nil {
+				return suggestion, err
+			}
+		case "advice", "a":
+			h.showAdvice()
+		case "notes", "n":
+			h.DisplayNotes()
+		case "pass", "p":
+			return nil, nil
+		default:
+			C.Warn.Println("Unknown choice.")
+		}
+	}
+}
+
+func (c *LinerController) PromptAccusation(h *HumanPlayer) (map[string]string, error) {
+	input, err := readPrompt(c.line, "Make an accusation before your turn? (y/N) ")
+	if err != nil {
+		return nil, quitError(err)
+	}
+	if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(input)), "y") {
+		return nil, nil
+	}
+	C.Info.Println("Name one card of every category. A wrong accusation knocks you out.")
+	return c.promptCombination(h)
+}
+
+// PromptCardToShow lets the user choose which card to reveal. With a single
+// match there is no choice to make, so it is only announced.
+func (c *LinerController) PromptCardToShow(h *HumanPlayer, suggester string, suggestion map[string]string, canShow []string) string {
+	sorted := append([]string{}, canShow...)
+	sort.Strings(sorted)
+	C.Info.Printf("%s suggested %s, and you can disprove it.\n", colorizeCard(suggester), strings.Join(values(suggestion), ", "))
+	if len(sorted) == 1 {
+		C.Info.Printf("You show %s, your only matching card.\n", colorizeCard(sorted[0]))
+		return sorted[0]
+	}
+	card, err := promptForSelection(c.line, fmt.Sprintf("Which card will you show %s?", suggester), sorted)
+	if err != nil {
+		// The game cannot wait for an answer that will never come.
+		C.Info.Printf("You show %s.\n", colorizeCard(sorted[0]))
+		return sorted[0]
+	}
+	return card
+}
+
+// quitError treats Ctrl-C at a turn prompt like the end of input, as the
+// other prompt helpers do outside card entry.
+func quitError(err error) error {
+	if err == liner.ErrPromptAborted {
+		return ErrUserQuit
+	}
+	return err
+}
+
+// promptCombination reads one card of each category, or returns nil.
+func (c *LinerController) promptCombination(h *HumanPlayer) (map[string]string, error) {
+	cards, err := promptForCards(c.line, false, len(h.cfg.Categories))
+	if err != nil {
+		return nil, err
+	}
+	combo := make(map[string]string)
+	for _, card := range cards {
+		combo[h.cfg.CardToType[card]] = card
+	}
+	if !isCompleteSuggestion(h.cfg, combo) {
+		C.Warn.Printf("That needs exactly one card from each of: %s.\n", strings.Join(h.cfg.CategoryNames(), ", "))
+		return nil, nil
+	}
+	return combo, nil
+}
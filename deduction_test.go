@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOwnDisprovedSuggestionsIntersect(t *testing.T) {
+	s, w, r := config.CardsOf("suspects"), config.CardsOf("weapons"), config.CardsOf("rooms")
+	ai := threePlayerBrain()
+	// Left holds six cards, five of them already known.
+	for _, card := range []string{s[1], s[2], w[2], w[3], r[2]} {
+		ai.ProcessTurnInfo("Game Event", "Left", card, nil)
+	}
+	// Left disproves two of our suggestions without us seeing the card. The
+	// one card Left has left must answer both, so it is the shared suspect.
+	ai.ProcessTurnInfo("Me", "Left", "", map[string]string{"suspects": s[0], "weapons": w[0], "rooms": r[0]})
+	if got := ai.knowledge[s[0]]["Left"]; got != StatusMaybe {
+		t.Fatalf("after one mystery %s with Left is %s, want Maybe", s[0], got)
+	}
+	ai.ProcessTurnInfo("Me", "Left", "", map[string]string{"suspects": s[0], "weapons": w[1], "rooms": r[1]})
+
+	if got := ai.knowledge[s[0]]["Left"]; got != StatusYes {
+		t.Errorf("%s with Left is %s, want Yes", s[0], got)
+	}
+	for _, card := range []string{w[0], r[0], w[1], r[1]} {
+		if got := ai.knowledge[card]["Left"]; got != StatusNo {
+			t.Errorf("%s with Left is %s, want No", card, got)
+		}
+	}
+	if len(ai.unresolvedSuggestions) != 0 {
+		t.Errorf("open mysteries = %+v, want none", ai.unresolvedSuggestions)
+	}
+}
+
+func TestSolvedCategoryDistributionIsOneHot(t *testing.T) {
+	suspects := config.CardsOf("suspects")
+	answer := suspects[len(suspects)-1]
+	ai := threePlayerBrain()
+	// Every other suspect is shown to us, so the last one must be the answer.
+	for i, card := range suspects[:len(suspects)-1] {
+		ai.ProcessTurnInfo("Game Event", []string{"Left", "Right"}[i%2], card, nil)
+	}
+	if got := ai.knowledge[answer]["solution"]; got != StatusYes {
+		t.Fatalf("%s in the solution is %s, want Yes", answer, got)
+	}
+	for card, p := range ai.SolutionDistribution("suspects") {
+		want := 0.0
+		if card == answer {
+			want = 1
+		}
+		if p != want {
+			t.Errorf("P(%s) = %v, want %v", card, p, want)
+		}
+	}
+
+	total := 0.0
+	for _, p := range ai.SolutionDistribution("weapons") {
+		total += p
+	}
+	if total < 0.999 || total > 1.001 {
+		t.Errorf("unsolved weapons distribution sums to %v, want 1", total)
+	}
+}
+
+func TestGeneratedLogReplaysConsistently(t *testing.T) {
+	for _, openHands := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "game.log")
+		var err error
+		captureStdout(t, func() { err = runGenerateLog(2, path, 4, openHands) })
+		if err != nil {
+			t.Fatalf("runGenerateLog: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		header := make(map[string][]string)
+		for _, line := range strings.Split(string(data), "\n") {
+			if key, value, ok := strings.Cut(strings.TrimPrefix(line, "# "), ": "); ok && strings.HasPrefix(line, "# ") {
+				header[key] = strings.Split(value, ", ")
+			}
+		}
+		ai := NewAdvancedAIBrain()
+		ai.Setup(config, header["players"], header["me"][0])
+		ai.ReceiveHand(header["hand"])
+
+		turns, _, err := readTurnLog(bytes.NewReader(data), ai.players)
+		if err != nil {
+			t.Fatalf("readTurnLog: %v", err)
+		}
+		if len(turns) == 0 {
+			t.Fatal("the log has no turns")
+		}
+		for i, turn := range turns {
+			if err := ai.ValidateTurn(turn.Suggester, turn.Disprover, turn.Shown, turn.Suggestion); err != nil {
+				t.Fatalf("open hands %t, turn %d (%v) contradicts the notes: %v", openHands, i+1, turn, err)
+			}
+			ai.ReplayEntries([]TurnRecord{turn})
+		}
+		if bad := ai.Contradictions(); len(bad) > 0 {
+			t.Errorf("open hands %t: replayed notes contradict themselves: %v", openHands, bad)
+		}
+		for _, card := range header["hand"] {
+			if got := ai.knowledge[card][ai.name]; got != StatusYes {
+				t.Errorf("own card %s is %s, want Yes", card, got)
+			}
+		}
+	}
+}
+
+func TestLuckEstimateForStrongAndImpossibleHands(t *testing.T) {
+	suspects, weapons := config.CardsOf("suspects"), config.CardsOf("weapons")
+
+	// Every suspect and weapon but the first: two categories are solved by
+	// the deal alone.
+	ai := threePlayerBrain()
+	ai.ReceiveHand(append(append([]string{}, suspects[1:]...), weapons[1:]...))
+	if got := ai.LuckEstimate(); !strings.HasPrefix(got, "Well ahead") {
+		t.Errorf("hand covering two categories: got %q, want a well-ahead verdict", got)
+	}
+
+	// A hand with every suspect leaves the solution nowhere to hide.
+	ai = threePlayerBrain()
+	ai.ReceiveHand(suspects)
+	if got := ai.LuckEstimate(); !strings.HasPrefix(got, "No verdict") {
+		t.Errorf("hand holding a whole category: got %q, want no verdict", got)
+	}
+
+	ai = threePlayerBrain()
+	ai.ReceiveHand([]string{suspects[0], weapons[0]})
+	ai.knowledge[suspects[0]]["Left"] = StatusYes
+	if got := ai.LuckEstimate(); !strings.HasPrefix(got, "No verdict: the notes contradict") {
+		t.Errorf("contradictory notes: got %q, want no verdict", got)
+	}
+}
+
+func TestMostValuableFactFindsTheCascade(t *testing.T) {
+	suspects, weapons, rooms := config.CardsOf("suspects"), config.CardsOf("weapons"), config.CardsOf("rooms")
+	ai := threePlayerBrain()
+	// Only the first two suspects can be the murderer, and Right lacks the
+	// first. Ruling the first out of the solution puts it with Left and
+	// makes the second the murderer, which settles it everywhere else too.
+	ai.ReceiveHand(append(append([]string{}, suspects[2:]...), weapons[0], rooms[0]))
+	ai.knowledge[suspects[0]]["Right"] = StatusNo
+	before := ai._knownCellCount()
+
+	card, location, impact := ai.MostValuableFact()
+	if card != suspects[0] || location != "solution" || impact != 4 {
+		t.Errorf("MostValuableFact = %s, %s, %d; want %s, solution, 4", card, location, impact, suspects[0])
+	}
+	if got := ai._knownCellCount(); got != before {
+		t.Errorf("trying facts changed the notes: %d known cells, want %d", got, before)
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	suspects, weapons, rooms := config.CardsOf("suspects"), config.CardsOf("weapons"), config.CardsOf("rooms")
+	ai := threePlayerBrain()
+	ai.ReceiveHand([]string{suspects[0], weapons[0], rooms[0]})
+	knowledge := ai.deepCopyKnowledge()
+
+	clone := ai.Clone()
+	clone.ProcessTurnInfo("Me", "Left", suspects[1], map[string]string{"suspects": suspects[1], "weapons": weapons[1], "rooms": rooms[1]})
+	clone.ProcessTurnInfo("Left", "Right", "", map[string]string{"suspects": suspects[2], "weapons": weapons[2], "rooms": rooms[2]})
+	clone.hand[suspects[3]] = struct{}{}
+	if err := clone.RecordDisclosure("Left", suspects[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if !maps.EqualFunc(ai.knowledge, knowledge, maps.Equal) {
+		t.Error("changing the clone's notes changed the original's")
+	}
+	if len(ai.history) != 0 || len(ai.unresolvedSuggestions) != 0 || ai.turnsSeen != 0 {
+		t.Errorf("the original has %d history entries, %d mysteries and %d turns seen, want none",
+			len(ai.history), len(ai.unresolvedSuggestions), ai.turnsSeen)
+	}
+	if _, ok := ai.hand[suspects[3]]; ok {
+		t.Error("a card added to the clone's hand appeared in the original's")
+	}
+	if len(ai.disclosed) != 0 {
+		t.Errorf("the original records disclosures %v made by the clone", ai.disclosed)
+	}
+}
+
+func TestWinningDeductionNamesTheFinalRule(t *testing.T) {
+	suspects, weapons, rooms := config.CardsOf("suspects"), config.CardsOf("weapons"), config.CardsOf("rooms")
+	ai := threePlayerBrain()
+	// Our hand places the murderer by elimination; nobody can answer our
+	// suggestion, so the weapon and the room follow from that alone.
+	ai.ReceiveHand(append(slices.Clone(suspects[1:]), weapons[1]))
+	if got := ai.WinningDeduction(); got != "" {
+		t.Fatalf("WinningDeduction before the solution is known = %q, want empty", got)
+	}
+	ai.ProcessTurnInfo("Me", "", "", map[string]string{"suspects": suspects[0], "weapons": weapons[0], "rooms": rooms[0]})
+	if got := ai.WinningDeduction(); got != RuleUndisproved {
+		t.Errorf("WinningDeduction = %q, want %q", got, RuleUndisproved)
+	}
+
+	// In a played game the winner's outcome carries the rule that placed its
+	// last solution card.
+	g := newSeededGame(t, 4, 5)
+	g.Play(defaultTurnLimit)
+	history := g.History()
+	if !g.Finished() || len(history) == 0 {
+		t.Fatal("the game did not finish")
+	}
+	last := history[len(history)-1]
+	winner := last.Player.(*AdvancedAIBrain)
+	var final string
+	for _, d := range winner.Reasoning() {
+		if d.Location == "solution" {
+			final = d.Reason
+		}
+	}
+	if !last.Correct || last.WinningDeduction == "" || last.WinningDeduction != final {
+		t.Errorf("winning outcome has WinningDeduction %q, want %q", last.WinningDeduction, final)
+	}
+}
+
+func TestEmptyCardNeverReachesTheNotes(t *testing.T) {
+	suspects, weapons, rooms := config.CardsOf("suspects"), config.CardsOf("weapons"), config.CardsOf("rooms")
+	ai := threePlayerBrain()
+	ai.ReceiveHand([]string{suspects[0], weapons[0], rooms[0]})
+	known := ai._knownCellCount()
+
+	if got := ai._pickCard(nil); got != "" {
+		t.Errorf("_pickCard(nil) = %q, want empty", got)
+	}
+	if got := ai.ChooseCardToShow("Left", map[string]string{"suspects": suspects[1], "weapons": weapons[1], "rooms": rooms[1]}); got != "" {
+		t.Errorf("ChooseCardToShow with nothing to show = %q, want empty", got)
+	}
+	for _, suggestion := range []map[string]string{
+		{"suspects": "", "weapons": weapons[1], "rooms": rooms[1]},
+		{"weapons": weapons[1], "rooms": rooms[1]},
+	} {
+		ai.ProcessTurnInfo("Left", "Right", "", suggestion)
+		ai.ProcessTurnInfo("Left", "", "", suggestion)
+	}
+
+	if _, ok := ai.knowledge[""]; ok {
+		t.Error("the notes have a row for an empty card")
+	}
+	if got := ai._knownCellCount(); got != known {
+		t.Errorf("malformed suggestions changed the notes: %d known cells, want %d", got, known)
+	}
+	if len(ai.unresolvedSuggestions) != 0 || len(ai.history) != 0 || len(ai.disclosed) != 0 {
+		t.Errorf("malformed input left %d mysteries, %d history entries and disclosures %v",
+			len(ai.unresolvedSuggestions), len(ai.history), ai.disclosed)
+	}
+	for _, d := range ai.Reasoning() {
+		if d.Card == "" {
+			t.Errorf("a deduction places an empty card: %+v", d)
+		}
+	}
+}
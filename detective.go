@@ -0,0 +1,1364 @@
+// detective.go
+// Detective mode: a co-pilot session that tracks a real-life game.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/peterh/liner"
+)
+
+// detectiveSession is one user's co-pilot session: their input, their brain
+// and their color theme. Nothing in it is shared with other sessions.
+type detectiveSession struct {
+	line  InputSource
+	brain *AdvancedAIBrain
+	theme *Theme
+}
+
+func newDetectiveSession(line InputSource, theme *Theme) *detectiveSession {
+	return &detectiveSession{line: line, theme: theme}
+}
+
+func (s *detectiveSession) run() {
+	line, C := s.line, s.theme
+	C.Info.Println("\n--- Starting Detective Mode Co-Pilot ---")
+
+	// 1. Setup Wizard
+	if err := s.setup(); err != nil {
+		if !errors.Is(err, ErrUserQuit) {
+			C.Warn.Printf("Setup failed: %v\n", err)
+		}
+		C.Info.Println("Goodbye!")
+		return
+	}
+	s.brain.RenderNotes(C, nil)
+
+	s.printHelp()
+
+	// 2. Main Command Loop
+	for {
+		// Use a single, clear prompt. The help text is available via the 'help' command.
+		input, err := line.Prompt("(detective) ")
+		fmt.Printf("%s\n", input)
+		if err != nil {
+			if err != liner.ErrPromptAborted && err != io.EOF {
+				C.Warn.Printf("Error reading line: %v\n", err)
+			}
+			s.quit()
+			return
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+
+		line.AppendHistory(input)
+		parts := strings.Fields(input)
+		cmd := strings.ToLower(parts[0])
+		args := parts[1:]
+
+		if err := s.dispatch(cmd, args); errors.Is(err, errQuitCommand) {
+			C.Info.Println("Exiting detective mode.")
+			return
+		} else if err != nil {
+			if !errors.Is(err, ErrUserQuit) {
+				C.Warn.Printf("Error reading line: %v\n", err)
+			}
+			s.quit()
+			return
+		}
+	}
+}
+
+// errQuitCommand is how dispatch reports the quit command.
+var errQuitCommand = errors.New("quit command")
+
+// quit ends a session cut short by the end of input, offering to save the
+// logged turns first.
+func (s *detectiveSession) quit() {
+	C := s.theme
+	if len(s.brain.history) > 0 {
+		path, err := readPrompt(s.line, "Save this session before quitting? File name (blank to skip): ")
+		path = strings.TrimSpace(path)
+		switch {
+		case err != nil || path == "":
+			C.Info.Println("Session not saved.")
+		default:
+			s.handleSaveCommand([]string{path})
+		}
+	}
+	C.Info.Println("Goodbye!")
+}
+
+// setup runs the wizard that creates the session's brain.
+func (s *detectiveSession) setup() error {
+	line, C := s.line, s.theme
+	numPlayers, err := promptForInt(line, fmt.Sprintf("How many players are in the real game? (%d-%d): ", minPlayers, config.MaxPlayers()), minPlayers, config.MaxPlayers())
+	if err != nil {
+		return err
+	}
+	// Who passed on a suggestion is worked out from the seating, so the
+	// order matters; where it starts does not.
+	C.Info.Println("Enter the players in turn order, starting anywhere.")
+	var playerNames []string
+	for i := 0; i < numPlayers; i++ {
+		name, err := promptForString(line, fmt.Sprintf("Enter name for Player %d: ", i+1))
+		if err != nil {
+			return err
+		}
+		playerNames = append(playerNames, name)
+	}
+	myPlayerName, err := promptForSelection(line, "Which player are you?", playerNames)
+	if err != nil {
+		return err
+	}
+
+	C.Info.Println("\nSelect the cards in your hand. Type 'done' when finished.")
+	myHand, err := promptForCards(line, true, 0) // 0 means no exact count
+	if err != nil {
+		return err
+	}
+
+	// 2. Create the AI Brain
+	brain := config.AI.NewBrain()
+	brain.Setup(config, playerNames, myPlayerName)
+	s.setBrain(brain)
+	brain.ReceiveHand(myHand)
+
+	// Uneven deals depend on who was dealt first; ask rather than guess.
+	dealt := len(config.AllCards) - len(config.Categories)
+	if dealt%numPlayers != 0 {
+		lo, hi := dealt/numPlayers, dealt/numPlayers+1
+		C.Info.Printf("\nThe cards don't divide evenly: some players hold %d and some %d.\n", lo, hi)
+		sizes := make(map[string]int)
+		for _, p := range playerNames {
+			if p != myPlayerName {
+				if sizes[p], err = promptForInt(line, fmt.Sprintf("How many cards does %s hold? (%d-%d): ", p, lo, hi), lo, hi); err != nil {
+					return err
+				}
+			}
+		}
+		brain.SetHandSizes(sizes)
+	}
+
+	C.Info.Println("\nDetective Mode is active! Your co-pilot is ready.")
+	return nil
+}
+
+// dispatch runs one command. Commands that prompt return their prompt
+// errors, such as ErrUserQuit; the quit command returns errQuitCommand.
+func (s *detectiveSession) dispatch(cmd string, args []string) error {
+	C := s.theme
+	switch cmd {
+	case "log", "l":
+		return s.handleLogCommand()
+	case "reveal", "r":
+		return s.handleRevealCommand()
+	case "suggest", "s":
+		s.handleSuggestCommand(args)
+	case "suggestions", "ss":
+		s.handleSuggestionsCommand()
+	case "notes", "n":
+		s.brain.RenderNotes(C, nil)
+	case "hand":
+		s.handleHandCommand()
+	case "undo", "u":
+		s.handleUndoCommand()
+	case "edit", "e":
+		return s.handleEditCommand()
+	case "progress-chart", "pc":
+		s.handleProgressChartCommand()
+	case "status", "st":
+		s.handleStatusCommand()
+	case "advisors", "adv":
+		s.handleAdvisorsCommand()
+	case "whatif", "wi":
+		return s.handleWhatIfCommand()
+	case "accuse", "a":
+		return s.handleAccuseCommand()
+	case "why":
+		s.handleWhyCommand(args)
+	case "opponent", "op":
+		s.handleOpponentCommand(args)
+	case "simulate", "sim":
+		s.handleSimulateCommand(args)
+	case "luck":
+		C.Info.Println(s.brain.LuckEstimate())
+	case "pivot":
+		s.handlePivotCommand()
+	case "svg":
+		s.handleSVGCommand(args)
+	case "showed":
+		s.handleShowedCommand(args)
+	case "import":
+		s.handleImportCommand(args)
+	case "export-notes":
+		s.handleExportNotesCommand(args)
+	case "import-notes":
+		s.handleImportNotesCommand(args)
+	case "save":
+		s.handleSaveCommand(args)
+	case "load":
+		s.handleLoadCommand(args)
+	case "help", "h":
+		s.handleHelpCommand(args)
+	case "quit", "q":
+		return errQuitCommand
+	default:
+		C.Warn.Printf("Unknown command '%s'. Type 'help' for a list of commands.\n", cmd)
+	}
+	return nil
+}
+
+func (s *detectiveSession) handleHelpCommand(args []string) {
+	C := s.theme
+	if len(args) == 0 {
+		// General help
+		C.Header.Println("\n--- Cluedo Toolbox Help ---")
+		fmt.Println("This is the detective co-pilot mode. Log events from your real-life game, and the AI will track everything for you.")
+		fmt.Println("\nAvailable commands:")
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendRow(table.Row{"Command", "Alias", "Description"})
+		t.AppendSeparator()
+		t.AppendRows([]table.Row{
+			{"log", "l", "Log a full game turn (suggestion and result)."},
+			{"reveal", "r", "Log a single card revealed by a player."},
+			{"suggest [N]", "s", "Ask the AI co-pilot for a strategic suggestion (or its top N)."},
+			{"suggestions", "ss", "Show the co-pilot's top 3 suggestions and what each would teach you."},
+			{"notes", "n", "Display the AI's current detective notes grid."},
+			{"undo", "u", "Take back the last logged turn or reveal."},
+			{"edit", "e", "Delete or re-enter any earlier turn or reveal."},
+			{"hand", "ha", "Display the cards currently in your hand."},
+			{"progress-chart", "pc", "Chart solved solution categories over the logged turns."},
+			{"status", "st", "Summarize how close the co-pilot is to solving."},
+			{"advisors", "adv", "Compare suggestions from co-pilots with different styles."},
+			{"whatif", "wi", "Preview what a suggestion and its answer would teach you."},
+			{"accuse", "a", "Check an accusation against your notes before you make it."},
+			{"why <card>", "", "Explain how the co-pilot worked out where a card is."},
+			{"opponent <player>", "op", "Show what one player is known or suspected to hold."},
+			{"simulate [N]", "sim", "Play the game out N times to find the fastest path to the solution."},
+			{"luck", "", "Estimate whether you are ahead of or behind an average player."},
+			{"pivot", "", "Show the one fact that would unlock the most deductions."},
+			{"svg <file>", "", "Save the notes grid as an SVG image."},
+			{"showed <player> <card>", "", "Record a card you showed to an opponent."},
+			{"import <file>", "", "Log every turn in a turn log file, e.g. from a paper notebook."},
+			{"export-notes [file]", "", "Write just the notes grid as JSON, to share with a friend."},
+			{"import-notes <file>", "", "Replace the notes grid with one from export-notes."},
+			{"save <file>", "", "Save this session to resume it later."},
+			{"load <file>", "", "Resume a saved session, replacing the current one."},
+			{"quit", "q", "Exit detective mode."},
+		})
+		t.SetStyle(tableStyle(table.StyleLight))
+		t.Render()
+
+		fmt.Println("\nType 'help <command>' for more details on a specific command (e.g., 'help log').")
+		return
+	}
+
+	// Specific help for a command
+	command := strings.ToLower(args[0])
+	C.Header.Printf("\n--- Help for: %s ---\n", command)
+	switch command {
+	case "log", "l":
+		fmt.Println("Records one full turn of a real game into the notebook.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  log")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  You will be interactively prompted for:")
+		fmt.Println("  1. The Suggester: The player making the suggestion.")
+		fmt.Println("  2. The Cards: One suggested card per category (suspect, weapon, room, ...).")
+		fmt.Println("  3. The Disprover: The player who showed a card. Select 'No One' if applicable.")
+		fmt.Println("  4. The Revealed Card (optional): If you were the suggester, you will be asked which card you were shown.")
+		fmt.Println("\nCards can be entered by their full name or by their ID number from the 'notes' table.")
+
+	case "reveal", "r":
+		fmt.Println("Records that a player revealed a specific card outside of a normal suggestion.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  reveal")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  Useful for game variants with Intrigue Cards or house rules.")
+		fmt.Println("  You will be prompted for the player and the card they revealed.")
+
+	case "suggest", "s":
+		fmt.Println("Asks the AI co-pilot for a strategically valuable suggestion for your turn.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  suggest")
+		fmt.Println("  suggest <N>")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  The AI will analyze its current knowledge to propose a suggestion that either:")
+		fmt.Println("  - Exploits known information to confirm the final piece of the solution.")
+		fmt.Println("  - Performs a 'Surgical Strike' to solve an outstanding mystery.")
+		fmt.Println("  - Explores to gather new information if no other strategy is viable.")
+		fmt.Println("  With a number, it lists its top N useful suggestions ranked by estimated information gain.")
+
+	case "suggestions", "ss":
+		fmt.Println("Lists the AI co-pilot's three most useful suggestions with the reasoning behind each.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  suggestions")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  Each line names the strategy that proposed the suggestion and what it hopes to")
+		fmt.Println("  learn. Nothing is logged; pick one and play it, then log what happened.")
+
+	case "notes", "n":
+		fmt.Println("Displays the AI's current detective notes grid.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  notes")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  This shows what the AI knows about every card, player, and the solution.")
+		fmt.Println("  (✔ = Yes, ✖ = No, ? = Maybe)")
+
+	case "hand", "ha":
+		fmt.Println("Displays the cards you entered as being in your hand at the start.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  hand")
+
+	case "undo", "u":
+		fmt.Println("Takes back the last 'log' or 'reveal', e.g. after a typo.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  undo")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  The notes are rebuilt from your hand and every remaining entry. Repeat to go further back.")
+
+	case "edit", "e":
+		fmt.Println("Fixes one earlier 'log' or 'reveal' that turned out to be wrong.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  edit")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  Lists every entry with its number. Pick one to delete it, or to enter it again")
+		fmt.Println("  through the usual prompts. The notes are then rebuilt from your hand and every")
+		fmt.Println("  entry in order, so later deductions are redone rather than patched.")
+
+	case "progress-chart", "pc":
+		fmt.Println("Shows how many of the solution categories were solved after each logged turn or reveal.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  progress-chart")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  Each character is one entry, from empty (nothing solved) to full (all solved).")
+		fmt.Println("  With -ascii the counts are printed as digits instead.")
+
+	case "status", "st":
+		fmt.Println("Summarizes how much is still unknown, without the full notes grid.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  status")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  For each category it shows how many cards may still be the solution and")
+		fmt.Println("  how many cards have not been placed with anyone yet, followed by the")
+		fmt.Println("  number of unresolved mysteries (turns where we don't know which card was shown).")
+
+	case "advisors", "adv":
+		fmt.Println("Asks several co-pilots with different playing styles for their suggestion.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  advisors")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  Every advisor reads the same notes, so they agree on the facts; they differ")
+		fmt.Println("  in which strategies they try and in how sure they must be before accusing.")
+		fmt.Println("  Each row shows the advisor's suggestion, its estimated information gain,")
+		fmt.Println("  and whether that advisor would accuse now.")
+
+	case "whatif", "wi":
+		fmt.Println("Shows what you would learn from a suggestion, without logging it.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  whatif")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  You are asked for the cards you might suggest, who would disprove it and")
+		fmt.Println("  the card they would show you. The co-pilot works it through on a copy of")
+		fmt.Println("  your notes and highlights what would change; your real notes are untouched.")
+
+	case "accuse", "a":
+		fmt.Println("Checks an accusation you are thinking of making against your notes.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  accuse")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  Name one card of every category. The verdict is WRONG if your notes rule")
+		fmt.Println("  any of them out, CERTAIN if they prove all of them, and otherwise shows how")
+		fmt.Println("  likely each card is. Nothing is logged.")
+
+	case "why":
+		fmt.Println("Explains how the co-pilot placed a card.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  why <card>")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  Shows the rule that placed the card and which logged entry it followed.")
+		fmt.Println("  For a solution card found by elimination, it also shows how every other")
+		fmt.Println("  card of its category was placed elsewhere.")
+
+	case "opponent", "op":
+		fmt.Println("Shows your notes from one player's point of view.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  opponent <player>")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  Lists the cards the player surely holds, the cards they might hold (with")
+		fmt.Println("  how likely each is), the suggestions they disproved with an unknown card,")
+		fmt.Println("  and how many of their cards are still unknown. Players can be given by")
+		fmt.Println("  name or seat number.")
+
+	case "simulate", "sim":
+		fmt.Println("Plans your endgame by playing the rest of the game out many times.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  simulate [N]")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  The co-pilot makes up N deals (default 20) that fit your notes and, for each")
+		fmt.Println("  of its best few suggestions, counts how many of your turns it takes to")
+		fmt.Println("  force the solution if opponents always show a legal card. Their own turns")
+		fmt.Println("  are not modelled, so real games usually go a little faster.")
+
+	case "luck":
+		fmt.Println("Gives a light-hearted estimate of how lucky your game has been so far.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  luck")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  Compares how many possible solutions your hand ruled out with a typical hand,")
+		fmt.Println("  and how quickly the rest have been eliminated with a typical game.")
+
+	case "pivot":
+		fmt.Println("Shows the single unknown fact that would trigger the most further deductions.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  pivot")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  Every unknown cell is tried as \"this player does not have this card\",")
+		fmt.Println("  and the one that lets the co-pilot deduce the most is reported.")
+		fmt.Println("  Try to find that fact out next, e.g. by suggesting the card.")
+
+	case "svg":
+		fmt.Println("Saves the current notes grid as an SVG image, e.g. for a game report.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  svg <file>")
+
+	case "showed":
+		fmt.Println("Records that you showed one of your cards to an opponent.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  showed <player> <card>")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  Players and cards can be given by name or by number, e.g. 'showed 2 Rope'.")
+		fmt.Println("  It does not change your notes, but lists what that opponent has learned from you.")
+
+	case "import":
+		fmt.Println("Logs the turns written in a text file, in order.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  import <file>")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  One turn per line: suggester | card1, card2, card3 | disprover | shown")
+		fmt.Println("  Disprover is 'none' if nobody could disprove; leave out shown (or write '?')")
+		fmt.Println("  if you did not see the card. Cards may be numbers. Lines starting with '#'")
+		fmt.Println("  are ignored, so logs written by 'generate-log' can be imported too.")
+		fmt.Println("  Nothing is imported if any line has a mistake. A turn that contradicts your")
+		fmt.Println("  notes stops the import there; the turns before it stay logged.")
+
+	case "export-notes":
+		fmt.Println("Writes the notes grid, and nothing else, as JSON.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  export-notes [file]")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  Without a file the JSON is printed, ready to paste into a chat. Unlike")
+		fmt.Println("  'save' it leaves out your hand and logged turns.")
+
+	case "import-notes":
+		fmt.Println("Replaces your notes grid with one written by 'export-notes'.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  import-notes <file>")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  The grid must be for the same players and card set. Your logged turns are")
+		fmt.Println("  kept, but 'undo' and 'edit' rebuild the notes from them alone.")
+
+	case "save":
+		fmt.Println("Saves your notes, hand and logged turns to a JSON file.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  save <file>")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  Use 'load <file>' in a later sitting to carry on where you left off.")
+
+	case "load":
+		fmt.Println("Replaces the current session with one saved by 'save'.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  load <file>")
+		C.Prompt.Println("\nDetails:")
+		fmt.Println("  The file must have been saved with the same card set (see -config).")
+
+	case "quit", "q":
+		fmt.Println("Exits detective mode and returns to the main application menu.")
+		C.Prompt.Println("\nUsage:")
+		fmt.Println("  quit")
+
+	default:
+		C.Warn.Printf("Unknown command '%s'. Type 'help' for a list of commands.\n", command)
+	}
+}
+
+func (s *detectiveSession) handleHandCommand() {
+	C := s.theme
+	C.Header.Println("\n--- Your Hand ---")
+	for _, card := range s.brain.Hand() {
+		C.Info.Println(" - " + C.Card(card))
+	}
+}
+
+func (s *detectiveSession) handleLogCommand() error {
+	line, ai, C := s.line, s.brain, s.theme
+	C.Info.Println("\n--- Log a Game Turn ---")
+
+	// --- THE FIX: Use promptForSelection for player names ---
+	playerNames := ai.players
+	suggester, err := promptForSelection(line, "Who made the suggestion?", playerNames)
+	if err != nil {
+		return err
+	}
+
+	numCards := len(config.Categories)
+	C.Info.Printf("What %d cards were suggested? (Use numbers or names)\n", numCards)
+	// The promptForCards helper is only for cards.
+	suggestionCards, err := promptForCards(line, false, numCards) // One card per category
+	if err != nil {
+		return err
+	}
+	if len(suggestionCards) != numCards {
+		C.Warn.Printf("Error: A suggestion must have exactly %d cards.\n", numCards)
+		return nil
+	}
+	suggestion := make(map[string]string)
+	for _, card := range suggestionCards {
+		suggestion[config.CardToType[card]] = card
+	}
+
+	disproverOptions := append(playerNames, "No One")
+	disprover, err := promptForSelection(line, "Who disproved the suggestion?", disproverOptions)
+	if err != nil {
+		return err
+	}
+
+	var revealedCard string
+	if disprover != "No One" && suggester == ai.Name() {
+		C.Info.Println("What card were you shown? (Use numbers or names, Ctrl-C if unknown)")
+		// Use promptForCards to get a single card.
+		revealedCards, err := promptForCards(line, true, 1)
+		if err != nil {
+			return err
+		}
+		if len(revealedCards) > 0 {
+			revealedCard = revealedCards[0]
+		}
+	} else if disprover == "No One" {
+		disprover = ""
+	}
+
+	if revealedCard != "" && ai.knowledge[revealedCard][disprover] == StatusNo {
+		if ok, err := s.resolveRevealConflict(disprover, revealedCard, s.handleLogCommand); !ok || err != nil {
+			return err
+		}
+	} else if err := ai.ValidateTurn(suggester, disprover, revealedCard, suggestion); err != nil {
+		C.No.Printf("This turn contradicts your notes: %v.\n", err)
+		choice, err := promptForSelection(line, "What would you like to do?", []string{"Re-enter the turn", "Log it anyway", "Discard it"})
+		switch {
+		case err != nil:
+			return err
+		case choice == "Re-enter the turn":
+			return s.handleLogCommand()
+		case choice == "Discard it":
+			C.Info.Println("Turn discarded.")
+			return nil
+		}
+	}
+
+	before := ai.deepCopyKnowledge()
+	ai.ProcessTurnInfo(suggester, disprover, revealedCard, suggestion)
+	if passed := ai._passedPlayers(suggester, disprover); len(passed) > 0 {
+		C.Info.Printf("Going by turn order (%s), these players passed and hold none of those cards: %s.\n", strings.Join(ai.players, ", "), strings.Join(passed, ", "))
+	}
+	C.Info.Println("Turn logged. Here are your updated notes (changes highlighted):")
+	ai.RenderNotes(C, changedCells(before, ai.knowledge))
+	return nil
+}
+
+func (s *detectiveSession) handleRevealCommand() error {
+	line, ai, C := s.line, s.brain, s.theme
+	C.Info.Println("\n--- Log a Revealed Card ---")
+	player, err := promptForSelection(line, "Which player revealed a card?", ai.players)
+	if err != nil {
+		return err
+	}
+
+	C.Info.Println("Which card did they reveal? (Use number or name)")
+	revealedCards, err := promptForCards(line, true, 1)
+	if err != nil || len(revealedCards) == 0 {
+		return err // Nil if the user cancelled.
+	}
+	card := revealedCards[0]
+	if ai.knowledge[card][player] == StatusNo {
+		if ok, err := s.resolveRevealConflict(player, card, s.handleRevealCommand); !ok || err != nil {
+			return err
+		}
+	}
+
+	// We can use ProcessTurnInfo with a special suggester to log this fact.
+	// This will call _markCardLocation correctly.
+	before := ai.deepCopyKnowledge()
+	ai.ProcessTurnInfo("Game Event", player, card, nil)
+	C.Info.Println("Revealed card logged.")
+	ai.RenderNotes(C, changedCells(before, ai.knowledge))
+	return nil
+}
+
+// resolveRevealConflict is called when player is said to have shown card
+// although the notes say they cannot hold it. It explains why and lets the
+// user re-enter the entry (via reenter), undo the earlier entries the belief
+// came from, or drop the new entry. It reports whether to go on logging it.
+func (s *detectiveSession) resolveRevealConflict(player, card string, reenter func() error) (bool, error) {
+	line, ai, C := s.line, s.brain, s.theme
+	n := ai.ConflictSource(player, card)
+	reason := ""
+	for loc, status := range ai.knowledge[card] {
+		if status == StatusYes {
+			reason = fmt.Sprintf(" (it is placed with %s)", loc)
+		}
+	}
+	C.No.Printf("Your notes say %s cannot hold %s%s.\n", player, card, reason)
+
+	options := []string{"Re-enter it", "Discard it"}
+	if n < 0 {
+		C.Info.Println("That follows from your own hand, so this entry must be wrong.")
+	} else {
+		C.Info.Printf("That follows from your last %d logged entries:\n", n)
+		for i, t := range ai.history[len(ai.history)-n:] {
+			fmt.Printf("  %d. %s\n", len(ai.history)-n+i+1, t)
+		}
+		options = []string{"Re-enter it", "Undo those entries and log this one", "Discard it"}
+	}
+	choice, err := promptForSelection(line, "What would you like to do?", options)
+	switch {
+	case err != nil:
+		return false, err
+	case choice == "Re-enter it":
+		return false, reenter()
+	case choice == "Undo those entries and log this one":
+		if err := ai.Rewind(n); err != nil {
+			C.Warn.Printf("Could not undo: %v\n", err)
+			return false, nil
+		}
+		C.Info.Printf("Undid %d entries. Re-log any that were correct.\n", n)
+		return true, nil
+	}
+	C.Info.Println("Entry discarded.")
+	return false, nil
+}
+
+func (s *detectiveSession) handleSuggestCommand(args []string) {
+	brain, C := s.brain, s.theme
+	C.Header.Println("\n--- AI Co-Pilot Suggestion ---")
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			C.Warn.Printf("Invalid count '%s'. Usage: suggest <N>\n", args[0])
+			return
+		}
+		options := brain.UsefulSuggestions()
+		if len(options) > n {
+			options = options[:n]
+		}
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		header := table.Row{"#"}
+		for _, cat := range config.CategoryNames() {
+			header = append(header, cat)
+		}
+		t.AppendHeader(append(header, "Strategy", "Info Gain"))
+		for i, opt := range options {
+			row := table.Row{i + 1}
+			for _, cat := range config.CategoryNames() {
+				row = append(row, s.markSolutionCard(opt.Cards[cat]))
+			}
+			t.AppendRow(append(row, opt.Strategy, opt.InfoGain))
+		}
+		t.SetStyle(tableStyle(table.StyleLight))
+		t.Render()
+		C.Info.Printf("(%s = known solution card, %s = probe)\n", statusSymbol(C, StatusYes, false), statusSymbol(C, StatusMaybe, false))
+		return
+	}
+	suggestion := brain.MakeSuggestion()
+	var parts []string
+	for _, cat := range config.CategoryNames() {
+		parts = append(parts, s.markSolutionCard(suggestion[cat]))
+	}
+	C.Info.Printf("The AI suggests you propose: %s\n", strings.Join(parts, ", "))
+	C.Info.Printf("(%s = known solution card, %s = probe)\n", statusSymbol(C, StatusYes, false), statusSymbol(C, StatusMaybe, false))
+}
+
+// markSolutionCard follows a suggested card with ✔ if the co-pilot knows it
+// is in the solution, or ? if naming it is a probe.
+func (s *detectiveSession) markSolutionCard(card string) string {
+	status := StatusMaybe
+	if s.brain.knowledge[card]["solution"] == StatusYes {
+		status = StatusYes
+	}
+	return s.theme.Card(card) + " " + statusSymbol(s.theme, status, false)
+}
+
+func (s *detectiveSession) handleSuggestionsCommand() {
+	brain, C := s.brain, s.theme
+	options := brain.UsefulSuggestions()
+	if len(options) == 0 {
+		C.Warn.Println("The co-pilot has no useful suggestion to offer.")
+		return
+	}
+	if len(options) > 3 {
+		options = options[:3]
+	}
+	C.Header.Println("\n--- AI Co-Pilot Top Suggestions ---")
+	for i, opt := range options {
+		var cards []string
+		for _, cat := range config.CategoryNames() {
+			cards = append(cards, C.Card(opt.Cards[cat]))
+		}
+		C.Info.Printf("%d. %s\n", i+1, strings.Join(cards, ", "))
+		fmt.Printf("   %s: %s\n", opt.Strategy, brain.Rationale(opt))
+	}
+}
+
+func (s *detectiveSession) handleUndoCommand() {
+	C := s.theme
+	if len(s.brain.history) == 0 {
+		C.Warn.Println("Nothing to undo.")
+		return
+	}
+	last := s.brain.history[len(s.brain.history)-1]
+	if err := s.brain.Rewind(1); err != nil {
+		C.Warn.Printf("Could not undo: %v\n", err)
+		return
+	}
+	C.Info.Printf("Undid %s.\n", describeEntry(last))
+	s.brain.RenderNotes(C, nil)
+}
+
+// describeEntry names a logged turn or reveal for messages.
+func describeEntry(t TurnRecord) string {
+	if t.Suggester == "Game Event" {
+		return fmt.Sprintf("the reveal of %s by %s", t.Shown, t.Disprover)
+	}
+	return fmt.Sprintf("the turn: %s", t)
+}
+
+func (s *detectiveSession) handleEditCommand() error {
+	line, ai, C := s.line, s.brain, s.theme
+	if len(ai.history) == 0 {
+		C.Warn.Println("Nothing logged yet.")
+		return nil
+	}
+	C.Header.Println("\n--- Logged Entries ---")
+	for i, t := range ai.history {
+		fmt.Printf("  %d. %s\n", i+1, describeEntry(t))
+	}
+	n, err := promptForInt(line, "Which entry? (0 to cancel) ", 0, len(ai.history))
+	if err != nil || n == 0 {
+		return err
+	}
+	entry := ai.history[n-1]
+	before := ai.deepCopyKnowledge()
+	choice, err := promptForSelection(line, "What would you like to do?", []string{"Delete it", "Re-enter it", "Cancel"})
+	if err != nil {
+		return err
+	}
+	switch choice {
+	case "Delete it":
+		if err := ai.DeleteEntry(n - 1); err != nil {
+			C.Warn.Printf("Could not delete: %v\n", err)
+			return nil
+		}
+		C.Info.Printf("Deleted %s.\n", describeEntry(entry))
+	case "Re-enter it":
+		// Go back to just before the entry, log it afresh, then put back the rest.
+		later := slices.Clone(ai.history[n:])
+		if err := ai.Rewind(len(ai.history) - n + 1); err != nil {
+			C.Warn.Printf("Could not edit: %v\n", err)
+			return nil
+		}
+		C.Info.Printf("Re-entering entry %d; the %d later entries will be replayed afterwards.\n", n, len(later))
+		if entry.Suggester == "Game Event" {
+			err = s.handleRevealCommand()
+		} else {
+			err = s.handleLogCommand()
+		}
+		if len(ai.history) < n {
+			C.Info.Printf("Entry %d was not re-entered, so it has been deleted.\n", n)
+		}
+		// Put the later entries back even if the user quit, so a save keeps them.
+		ai.ReplayEntries(later)
+		if err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	C.Info.Println("Notes rebuilt. Here they are (changes highlighted):")
+	ai.RenderNotes(C, changedCells(before, ai.knowledge))
+	return nil
+}
+
+func (s *detectiveSession) handleProgressChartCommand() {
+	C := s.theme
+	history := s.brain.ProgressHistory()
+	if len(history) == 0 {
+		C.Warn.Println("Nothing logged yet.")
+		return
+	}
+	total := len(config.Categories)
+	C.Info.Printf("Solved categories over %d entries: %s (now %d/%d)\n", len(history), sparkline(history, total, asciiOnly), history[len(history)-1], total)
+}
+
+// sparkline draws values from 0 to top as one character each, or as digits
+// in ASCII mode.
+func sparkline(values []int, top int, ascii bool) string {
+	levels := []rune{'▁', '▃', '▆', '█'}
+	var b strings.Builder
+	for _, v := range values {
+		v = max(0, min(v, top))
+		if ascii {
+			b.WriteString(strconv.Itoa(v))
+		} else {
+			b.WriteRune(levels[(v*(len(levels)-1)+top-1)/top])
+		}
+	}
+	return b.String()
+}
+
+func (s *detectiveSession) handleStatusCommand() {
+	C := s.theme
+	summary := s.brain.Summary()
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Category", "Candidates", "Unplaced cards"})
+	for _, cat := range summary.Categories {
+		candidates := fmt.Sprint(cat.Candidates)
+		if cat.Candidates == 1 {
+			candidates = C.Yes.Sprint("solved")
+		}
+		t.AppendRow(table.Row{cat.Name, candidates, fmt.Sprint(cat.Unplaced)})
+	}
+	t.SetStyle(tableStyle(table.StyleLight))
+	t.Render()
+	C.Info.Printf("Unresolved mysteries: %d\n", summary.Mysteries)
+	if summary.Solved() {
+		C.Yes.Println("Every category is solved. You can accuse!")
+	}
+}
+
+// promptCombination asks for one card of every category, e.g. for what the
+// user would suggest or accuse. It returns nil if the answer is incomplete,
+// with an error only if reading it failed.
+func (s *detectiveSession) promptCombination(verb string) (map[string]string, error) {
+	C := s.theme
+	C.Info.Printf("What %d cards would you %s? (Use numbers or names)\n", len(config.Categories), verb)
+	cards, err := promptForCards(s.line, false, len(config.Categories))
+	if err != nil {
+		return nil, err
+	}
+	combo := make(map[string]string)
+	for _, card := range cards {
+		combo[config.CardToType[card]] = card
+	}
+	if !isCompleteSuggestion(config, combo) {
+		C.Warn.Printf("That needs exactly one card from each of: %s.\n", strings.Join(config.CategoryNames(), ", "))
+		return nil, nil
+	}
+	return combo, nil
+}
+
+func (s *detectiveSession) handleAccuseCommand() error {
+	ai, C := s.brain, s.theme
+	C.Info.Println("\n--- Check an Accusation ---")
+	accusation, err := s.promptCombination("accuse")
+	if accusation == nil {
+		return err
+	}
+	var wrong []string
+	certain := true
+	for _, cat := range config.CategoryNames() {
+		card := accusation[cat]
+		switch ai.knowledge[card]["solution"] {
+		case StatusNo:
+			holder := "the solution is another card"
+			for _, p := range ai.players {
+				if ai.knowledge[card][p] == StatusYes && p == ai.Name() {
+					holder = "you hold it"
+				} else if ai.knowledge[card][p] == StatusYes {
+					holder = p + " holds it"
+				}
+			}
+			wrong = append(wrong, fmt.Sprintf("%s (%s)", C.Card(card), holder))
+			certain = false
+		case StatusMaybe:
+			certain = false
+		}
+	}
+	switch {
+	case len(wrong) > 0:
+		C.No.Println("WRONG. Your notes rule out:")
+		for _, w := range wrong {
+			fmt.Println("  " + w)
+		}
+		C.Warn.Println("Do not make this accusation.")
+	case certain:
+		C.Yes.Println("CERTAIN. Your notes prove every card. Go ahead and accuse!")
+	default:
+		probs := ai.Probabilities()
+		C.Warn.Println("POSSIBLE, but not proven. How likely each card is to be the solution:")
+		for _, cat := range config.CategoryNames() {
+			card := accusation[cat]
+			fmt.Printf("  %s: %s %.0f%%\n", cat, C.Card(card), probs[card]["solution"]*100)
+		}
+		C.Info.Println("A wrong accusation knocks you out; consider gathering more evidence.")
+	}
+	return nil
+}
+
+func (s *detectiveSession) handleWhyCommand(args []string) {
+	ai, C := s.brain, s.theme
+	card := lookupCard(strings.Join(args, " "))
+	if card == "" {
+		C.Warn.Println("Usage: why <card>")
+		return
+	}
+	chain := ai.Explain(card)
+	if len(chain) == 0 {
+		var open []string
+		for _, loc := range append(append([]string{}, ai.players...), "solution") {
+			if ai.knowledge[card][loc] == StatusMaybe {
+				open = append(open, loc)
+			}
+		}
+		if len(open) > 0 {
+			C.Info.Printf("%s is not placed yet; it could be with: %s.\n", C.Card(card), strings.Join(open, ", "))
+		} else {
+			C.Info.Printf("There is no record of how %s was placed (notes loaded from a file keep no reasoning).\n", C.Card(card))
+		}
+		return
+	}
+	for i, d := range chain {
+		when := "from the start"
+		if d.Entry > 0 {
+			when = fmt.Sprintf("after entry %d (%s)", d.Entry, describeEntry(ai.history[d.Entry-1]))
+		}
+		indent := ""
+		if i > 0 {
+			indent = "  "
+		}
+		if i == 1 {
+			C.Info.Println("  ...because every other card of its category is placed:")
+		}
+		fmt.Printf("%s%s is with %s, by %s, %s.\n", indent, C.Card(d.Card), d.Location, d.Reason, when)
+	}
+}
+
+func (s *detectiveSession) handleOpponentCommand(args []string) {
+	ai, C := s.brain, s.theme
+	player := s.lookupPlayer(strings.Join(args, " "))
+	if player == "" {
+		C.Warn.Println("Usage: opponent <player>")
+		return
+	}
+	var holds, maybe []string
+	ruledOut := 0
+	for _, card := range ai.config.AllCards {
+		switch ai.knowledge[card][player] {
+		case StatusYes:
+			holds = append(holds, card)
+		case StatusMaybe:
+			maybe = append(maybe, card)
+		default:
+			ruledOut++
+		}
+	}
+	probs := ai.Probabilities()
+	sort.SliceStable(maybe, func(i, j int) bool { return probs[maybe[i]][player] > probs[maybe[j]][player] })
+
+	C.Header.Printf("\n--- %s ---\n", C.Card(player))
+	if size, ok := ai.handSizes[player]; ok {
+		C.Info.Printf("Holds %d cards: %d known, %d still to find.\n", size, len(holds), size-len(holds))
+	}
+	C.Yes.Println("Holds:")
+	if len(holds) == 0 {
+		fmt.Println("  (nothing known yet)")
+	}
+	for _, card := range holds {
+		fmt.Println("  " + C.Card(card))
+	}
+	C.Maybe.Println("Might hold:")
+	if len(maybe) == 0 {
+		fmt.Println("  (nothing else)")
+	}
+	for _, card := range maybe {
+		fmt.Printf("  %s %.0f%%\n", C.Card(card), probs[card][player]*100)
+	}
+	for _, m := range ai.unresolvedSuggestions {
+		if m.Disprover == player {
+			C.Info.Printf("Showed one of: %s\n", strings.Join(sortedKeys(m.PossibleCards), ", "))
+		}
+	}
+	C.No.Printf("Ruled out: %d cards.\n", ruledOut)
+}
+
+func (s *detectiveSession) handleSimulateCommand(args []string) {
+	ai, C := s.brain, s.theme
+	rollouts := 20
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			C.Warn.Printf("Invalid count '%s'. Usage: simulate [N]\n", args[0])
+			return
+		}
+		rollouts = n
+	}
+	if len(ai._knownSolutionCards()) == len(config.Categories) {
+		C.Yes.Println("You already know the solution. Accuse!")
+		return
+	}
+	var plans []EndgamePlan
+	quietly(func() { plans = ai.PlanEndgame(5, rollouts, ai.rng) })
+	if plans == nil {
+		C.No.Println("No deal fits your notes; some logged entry must be wrong.")
+		return
+	}
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	header := table.Row{"#"}
+	for _, cat := range config.CategoryNames() {
+		header = append(header, cat)
+	}
+	t.AppendHeader(append(header, "Your Turns to Solve"))
+	for i, plan := range plans {
+		row := table.Row{i + 1}
+		for _, cat := range config.CategoryNames() {
+			row = append(row, C.Card(plan.Suggestion[cat]))
+		}
+		t.AppendRow(append(row, fmt.Sprintf("%.1f", plan.ExpectedTurns)))
+	}
+	t.SetStyle(tableStyle(table.StyleLight))
+	t.Render()
+	C.Info.Printf("Over %d simulated deals, suggesting %s first solves it in %.1f of your turns on average.\n",
+		plans[0].Rollouts, strings.Join(values(plans[0].Suggestion), ", "), plans[0].ExpectedTurns)
+}
+
+func (s *detectiveSession) handleWhatIfCommand() error {
+	line, C := s.line, s.theme
+	C.Info.Println("\n--- What If? ---")
+	suggestion, err := s.promptCombination("suggest")
+	if suggestion == nil {
+		return err
+	}
+	var others []string
+	for _, p := range s.brain.players {
+		if p != s.brain.Name() {
+			others = append(others, p)
+		}
+	}
+	disprover, err := promptForSelection(line, "Who would disprove it?", append(others, "No One"))
+	if err != nil {
+		return err
+	}
+	var shown string
+	if disprover == "No One" {
+		disprover = ""
+	} else {
+		C.Info.Println("What card would they show you? (Use numbers or names, Ctrl-C if you only want to know they have one)")
+		revealed, err := promptForCards(line, true, 1)
+		if err != nil {
+			return err
+		}
+		if len(revealed) > 0 {
+			shown = revealed[0]
+		}
+	}
+	me := s.brain.Name()
+	if err := s.brain.ValidateTurn(me, disprover, shown, suggestion); err != nil {
+		C.No.Printf("That could not happen: %v.\n", err)
+		return nil
+	}
+
+	hypo := s.brain.Clone()
+	quietly(func() { hypo.ProcessTurnInfo(me, disprover, shown, suggestion) })
+	changed := changedCells(s.brain.knowledge, hypo.knowledge)
+	if len(changed) == 0 {
+		C.Warn.Println("You would learn nothing new from that.")
+		return nil
+	}
+	hypo.RenderNotes(C, changed)
+	C.Info.Printf("You would fill in %d more cells of your notes.\n", len(changed))
+	before, after := s.brain._knownSolutionCards(), hypo._knownSolutionCards()
+	for _, cat := range config.CategoryNames() {
+		if before[cat] == "" && after[cat] != "" {
+			C.Yes.Printf("You would know the %s: %s.\n", cat, C.Card(after[cat]))
+		}
+	}
+	return nil
+}
+
+// advisor is one co-pilot style offered by the advisors command.
+type advisor struct {
+	Name       string
+	Strategies []string // Nil means DefaultStrategies.
+	AccuseAt   float64  // See AccusationPolicy; 0 means only when certain.
+}
+
+// detectiveAdvisors are the styles the advisors command compares.
+var detectiveAdvisors = []advisor{
+	{Name: "Standard"},
+	{Name: "Focused", Strategies: []string{StrategyExploit, StrategySurgical, StrategyFocus, StrategyExplore}},
+	{Name: "Curious", Strategies: []string{StrategyExploit, StrategySurgical, StrategyInfoGain}},
+	{Name: "Bold", AccuseAt: 0.8},
+}
+
+// handleAdvisorsCommand asks every advisor for its move. Advisors are
+// clones of the session's brain, so they always share its notes and never
+// need to be told about entries separately.
+func (s *detectiveSession) handleAdvisorsCommand() {
+	C := s.theme
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	header := table.Row{"Advisor"}
+	for _, cat := range config.CategoryNames() {
+		header = append(header, cat)
+	}
+	t.AppendHeader(append(header, "Info Gain", "Accuse?"))
+	for _, a := range detectiveAdvisors {
+		brain := s.brain.Clone().WithStrategies(a.Strategies...)
+		brain.accusation = AccusationPolicy{}
+		if a.AccuseAt > 0 {
+			brain.WithAccusationThreshold(a.AccuseAt)
+		}
+		row := table.Row{a.Name}
+		var accusation, suggestion map[string]string
+		quietly(func() {
+			accusation = brain.ShouldAccuse()
+			suggestion = brain.MakeSuggestion()
+		})
+		accuse := "no"
+		if accusation != nil {
+			accuse = C.Yes.Sprint("yes")
+		}
+		for _, cat := range config.CategoryNames() {
+			row = append(row, C.Card(suggestion[cat]))
+		}
+		t.AppendRow(append(row, brain._estimateInfoGain(suggestion), accuse))
+	}
+	t.SetStyle(tableStyle(table.StyleLight))
+	t.Render()
+	_, confidence := s.brain.BestGuessSolution()
+	C.Info.Printf("All advisors share the same notes; their best guess at the solution is %.0f%% likely.\n", confidence*100)
+}
+
+func (s *detectiveSession) handlePivotCommand() {
+	C := s.theme
+	card, location, impact := s.brain.MostValuableFact()
+	if card == "" {
+		C.Info.Println("No single fact would trigger further deductions right now.")
+		return
+	}
+	holder := C.Card(location)
+	if location == "solution" {
+		holder = "the solution"
+	}
+	C.Info.Printf("Learning that %s does not hold %s would unlock %d more deductions.\n", holder, C.Card(card), impact)
+}
+
+func (s *detectiveSession) handleSVGCommand(args []string) {
+	C := s.theme
+	if len(args) != 1 {
+		C.Warn.Println("Usage: svg <file>")
+		return
+	}
+	if err := writeNotesSVG(args[0], s.brain); err != nil {
+		C.Warn.Printf("Could not save SVG: %v\n", err)
+		return
+	}
+	C.Info.Printf("Notes saved to %s.\n", args[0])
+}
+
+func (s *detectiveSession) handleShowedCommand(args []string) {
+	C := s.theme
+	// Names may contain spaces, so try every split between player and card.
+	for i := 1; i < len(args); i++ {
+		player := s.lookupPlayer(strings.Join(args[:i], " "))
+		card := lookupCard(strings.Join(args[i:], " "))
+		if player == "" || card == "" {
+			continue
+		}
+		if err := s.brain.RecordDisclosure(player, card); err != nil {
+			C.Warn.Printf("Could not record that: %v\n", err)
+			return
+		}
+		var known []string
+		for _, c := range s.brain.OpponentKnows(player) {
+			known = append(known, C.Card(c))
+		}
+		C.Info.Printf("Noted. %s is known to have seen: %s\n", C.Card(player), strings.Join(known, ", "))
+		return
+	}
+	C.Warn.Println("Usage: showed <player> <card>")
+}
+
+// lookupPlayer resolves a player typed as their seat number or name
+// (case-insensitive). It returns "" if nothing matches.
+func (s *detectiveSession) lookupPlayer(input string) string {
+	players := s.brain.players
+	if num, err := strconv.Atoi(input); err == nil && num >= 1 && num <= len(players) {
+		return players[num-1]
+	}
+	for _, p := range players {
+		if strings.EqualFold(p, input) {
+			return p
+		}
+	}
+	return ""
+}
+
+func (s *detectiveSession) handleImportCommand(args []string) {
+	ai, C := s.brain, s.theme
+	if len(args) != 1 {
+		C.Warn.Println("Usage: import <file>")
+		return
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		C.Warn.Printf("Could not import: %v\n", err)
+		return
+	}
+	defer f.Close()
+	turns, lines, err := readTurnLog(f, ai.players)
+	if err != nil {
+		C.No.Printf("Nothing imported; %s has mistakes:\n", args[0])
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Println("  " + line)
+		}
+		return
+	}
+
+	before := ai.deepCopyKnowledge()
+	imported := 0
+	for i, t := range turns {
+		if err := ai.ValidateTurn(t.Suggester, t.Disprover, t.Shown, t.Suggestion); err != nil {
+			C.No.Printf("Line %d contradicts your notes: %v. Stopping there.\n", lines[i], err)
+			break
+		}
+		ai.ReplayEntries([]TurnRecord{t})
+		imported++
+	}
+	C.Info.Printf("Imported %d of %d turns from %s. Here are your updated notes (changes highlighted):\n", imported, len(turns), args[0])
+	ai.RenderNotes(C, changedCells(before, ai.knowledge))
+}
+
+func (s *detectiveSession) handleExportNotesCommand(args []string) {
+	C := s.theme
+	if len(args) > 1 {
+		C.Warn.Println("Usage: export-notes [file]")
+		return
+	}
+	data, err := s.brain.ExportKnowledge()
+	if err != nil {
+		C.Warn.Printf("Could not export notes: %v\n", err)
+		return
+	}
+	if len(args) == 0 {
+		fmt.Println(string(data))
+		return
+	}
+	if err := os.WriteFile(args[0], append(data, '\n'), 0644); err != nil {
+		C.Warn.Printf("Could not export notes: %v\n", err)
+		return
+	}
+	C.Info.Printf("Notes grid written to %s.\n", args[0])
+}
+
+func (s *detectiveSession) handleImportNotesCommand(args []string) {
+	C := s.theme
+	if len(args) != 1 {
+		C.Warn.Println("Usage: import-notes <file>")
+		return
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		C.Warn.Printf("Could not import notes: %v\n", err)
+		return
+	}
+	before := s.brain.deepCopyKnowledge()
+	if err := s.brain.ImportKnowledge(data); err != nil {
+		C.Warn.Printf("Could not import notes: %v\n", err)
+		return
+	}
+	C.Info.Printf("Notes grid loaded from %s (changes highlighted):\n", args[0])
+	s.brain.RenderNotes(C, changedCells(before, s.brain.knowledge))
+}
+
+func (s *detectiveSession) handleSaveCommand(args []string) {
+	C := s.theme
+	if len(args) != 1 {
+		C.Warn.Println("Usage: save <file>")
+		return
+	}
+	if err := s.brain.SaveSession(args[0]); err != nil {
+		C.Warn.Printf("Could not save session: %v\n", err)
+		return
+	}
+	C.Info.Printf("Session saved to %s.\n", args[0])
+}
+
+func (s *detectiveSession) handleLoadCommand(args []string) {
+	C := s.theme
+	if len(args) != 1 {
+		C.Warn.Println("Usage: load <file>")
+		return
+	}
+	brain, err := LoadSession(args[0])
+	if err != nil {
+		C.Warn.Printf("Could not load session: %v\n", err)
+		return
+	}
+	s.setBrain(brain)
+	C.Info.Printf("Resumed %s's session from %s.\n", brain.Name(), args[0])
+	brain.RenderNotes(C, nil)
+}
+
+// setBrain makes brain the session's co-pilot and has it speak up whenever
+// a mystery opens or closes and as soon as the solution is known.
+func (s *detectiveSession) setBrain(brain *AdvancedAIBrain) {
+	brain.OnMysteryCreated = func(disprover string, cards []string) {
+		var names []string
+		for _, card := range cards {
+			names = append(names, s.theme.Card(card))
+		}
+		s.theme.Info.Printf("%s Noted: %s holds one of %s\n", mysteryMark(), s.theme.Card(disprover), strings.Join(names, ", "))
+	}
+	brain.OnMysterySolved = func(disprover, card string) {
+		s.theme.Yes.Printf("%s Solved: %s had the %s\n", mysteryMark(), s.theme.Card(disprover), s.theme.Card(card))
+	}
+	brain.OnSolutionFound = func(solution map[string]string) {
+		var cards []string
+		for _, cat := range config.CategoryNames() {
+			cards = append(cards, s.theme.Card(solution[cat]))
+		}
+		s.theme.Header.Println("\n*** SOLVED! ***")
+		s.theme.Yes.Printf("The solution must be %s. You can accuse now!\n", strings.Join(cards, ", "))
+	}
+	s.brain = brain
+}
+
+// mysteryMark heads the lines announcing mysteries.
+func mysteryMark() string {
+	if asciiOnly {
+		return "[?]"
+	}
+	return "🔍"
+}
+
+func (s *detectiveSession) printHelp() {
+	fmt.Println(s.theme.Prompt.Sprint("\n(log, reveal, suggest, notes, quit)"))
+}
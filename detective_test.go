@@ -0,0 +1,222 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+func TestDetectiveScriptedSession(t *testing.T) {
+	s := newDetectiveSession(NewScriptedInput(
+		// Setup: three players, we are A holding Miss Scarlett, Candlestick and Kitchen.
+		"3", "A", "B", "C", "1", "1", "7", "13", "done",
+		// We suggest Colonel Mustard, Lead Pipe, Conservatory; B shows us the Lead Pipe.
+		"log", "1", "2", "9", "15", "2", "9",
+		// B suggests Mrs. White, Revolver, Dining Room; nobody can disprove.
+		"log", "2", "3", "10", "16", "4",
+		// C suggests Mr. Green, Rope, Billiard Room; B shows C a card.
+		"log", "3", "4", "11", "17", "2",
+		"suggest",
+		"quit",
+	), C)
+	s.run()
+
+	ai := s.brain
+	if ai == nil {
+		t.Fatal("setup did not create a brain")
+	}
+	if got := len(ai.history); got != 3 {
+		t.Fatalf("logged %d turns, want 3", got)
+	}
+	for _, tc := range []struct {
+		card, location string
+		want           CardStatus
+	}{
+		{"Miss Scarlett", "A", StatusYes},
+		{"Miss Scarlett", "solution", StatusNo},
+		{"Lead Pipe", "B", StatusYes},
+		{"Lead Pipe", "solution", StatusNo},
+		{"Mrs. White", "C", StatusNo},
+		{"Revolver", "A", StatusNo},
+		{"Mr. Green", "A", StatusNo},
+	} {
+		if got := ai.knowledge[tc.card][tc.location]; got != tc.want {
+			t.Errorf("%s with %s is %s, want %s", tc.card, tc.location, got, tc.want)
+		}
+	}
+	if len(ai.unresolvedSuggestions) != 1 || ai.unresolvedSuggestions[0].Disprover != "B" {
+		t.Errorf("open mysteries = %+v, want one for B", ai.unresolvedSuggestions)
+	}
+}
+
+func TestDetectiveRevealConflictPromptsForCorrection(t *testing.T) {
+	// Three players; we are A holding Miss Scarlett, Candlestick and Kitchen.
+	// Nobody disproves B's Colonel Mustard, Dagger, Ballroom, so C cannot
+	// hold Colonel Mustard. Then C is logged as showing us exactly that.
+	setup := []string{"3", "A", "B", "C", "1", "1", "7", "13", "done",
+		"log", "2", "2", "8", "14", "4"}
+	conflict := []string{"log", "1", "2", "9", "15", "3", "2"}
+	for _, tc := range []struct {
+		name        string
+		answer      []string
+		wantEntries int
+		want        CardStatus
+	}{
+		{"discard", []string{"3"}, 1, StatusNo},
+		{"undo the pass", []string{"2"}, 1, StatusYes},
+		{"re-enter with the right card", []string{"1", "1", "2", "9", "15", "3", "9"}, 2, StatusNo},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			script := append(append(append([]string{}, setup...), conflict...), tc.answer...)
+			s := newDetectiveSession(NewScriptedInput(append(script, "quit")...), C)
+			s.run()
+			ai := s.brain
+			if got := len(ai.history); got != tc.wantEntries {
+				t.Errorf("logged %d entries, want %d", got, tc.wantEntries)
+			}
+			if got := ai.knowledge["Colonel Mustard"]["C"]; got != tc.want {
+				t.Errorf("Colonel Mustard with C is %s, want %s", got, tc.want)
+			}
+			if bad := ai.Contradictions(); len(bad) > 0 {
+				t.Errorf("notes contradict themselves: %v", bad)
+			}
+		})
+	}
+}
+
+func TestDetectiveRevealOfOwnCardCannotBeUndone(t *testing.T) {
+	// C "shows" us Miss Scarlett, which is in our own hand: only re-entering
+	// or discarding is offered, so "2" discards.
+	s := newDetectiveSession(NewScriptedInput(
+		"3", "A", "B", "C", "1", "1", "7", "13", "done",
+		"log", "1", "1", "9", "15", "3", "1", "2",
+		"quit",
+	), C)
+	s.run()
+	if got := len(s.brain.history); got != 0 {
+		t.Errorf("logged %d entries, want the contradictory one discarded", got)
+	}
+	if got := s.brain.knowledge["Miss Scarlett"]["A"]; got != StatusYes {
+		t.Errorf("Miss Scarlett with A is %s, want Yes", got)
+	}
+}
+
+// adviceCards returns the cards named on the line of out that starts with
+// prefix, with the theme's markers stripped.
+func adviceCards(out, prefix string) []string {
+	for _, line := range strings.Split(out, "\n") {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			rest = strings.NewReplacer(" ✔", "", " ?", "").Replace(rest)
+			return strings.FieldsFunc(rest, func(r rune) bool { return r == ',' })
+		}
+	}
+	return nil
+}
+
+func TestDetectiveAdviceIsAValidSuggestion(t *testing.T) {
+	saved := color.NoColor
+	t.Cleanup(func() { color.NoColor = saved })
+	color.NoColor = true
+
+	// We are A holding Miss Scarlett, Candlestick and Kitchen, and ask for advice.
+	s := newDetectiveSession(NewScriptedInput("3", "A", "B", "C", "1", "1", "7", "13", "done", "suggest", "quit"), C)
+	out := captureStdout(t, s.run)
+
+	suggestion := make(map[string]string)
+	for _, name := range adviceCards(out, "The AI suggests you propose: ") {
+		card := strings.TrimSpace(name)
+		suggestion[config.CardToType[card]] = card
+	}
+	if !isCompleteSuggestion(config, suggestion) {
+		t.Fatalf("advice %v is not a full suggestion; output:\n%s", suggestion, out)
+	}
+	for _, card := range suggestion {
+		if s.brain.knowledge[card]["A"] == StatusYes {
+			t.Errorf("advice names our own %s", card)
+		}
+	}
+}
+
+func TestDetectiveSessionsKeepTheirOwnThemes(t *testing.T) {
+	saved := color.NoColor
+	t.Cleanup(func() { color.NoColor = saved })
+	color.NoColor = false
+
+	// marked prints its headers and notices on a magenta background.
+	marked := NewTheme()
+	marked.Header, marked.Info = color.New(color.BgHiMagenta), color.New(color.BgHiMagenta)
+	const magenta = "\x1b[105m"
+
+	session := func(theme *Theme) *detectiveSession {
+		return newDetectiveSession(NewScriptedInput("3", "A", "B", "C", "1", "1", "7", "13", "done", "status", "quit"), theme)
+	}
+	plain, fancy := session(C), session(marked)
+	plainOut := captureStdout(t, plain.run)
+	fancyOut := captureStdout(t, fancy.run)
+	plainAgain := captureStdout(t, session(C).run)
+
+	if !strings.Contains(fancyOut, magenta) {
+		t.Error("the marked session did not use its theme")
+	}
+	if strings.Contains(plainOut, magenta) || strings.Contains(plainAgain, magenta) {
+		t.Error("the default session picked up the other session's theme")
+	}
+	if plainOut != plainAgain {
+		t.Error("running a session with another theme changed the default session's output")
+	}
+}
+
+func TestProgressHistoryAndSparklineHaveOnePointPerEntry(t *testing.T) {
+	suspects, weapons, rooms := config.CardsOf("suspects"), config.CardsOf("weapons"), config.CardsOf("rooms")
+	ai := threePlayerBrain()
+	ai.ReceiveHand(append(slices.Clone(suspects[1:]), weapons[1]))
+	turns := []TurnRecord{
+		{Suggester: "Left", Suggestion: map[string]string{"suspects": suspects[0], "weapons": weapons[2], "rooms": rooms[1]}, Disprover: "Right"},
+		{Suggester: "Right", Suggestion: map[string]string{"suspects": suspects[1], "weapons": weapons[3], "rooms": rooms[2]}, Disprover: "Me", Shown: suspects[1]},
+		{Suggester: "Me", Suggestion: map[string]string{"suspects": suspects[0], "weapons": weapons[0], "rooms": rooms[0]}},
+	}
+	for _, turn := range turns {
+		ai.ProcessTurnInfo(turn.Suggester, turn.Disprover, turn.Shown, turn.Suggestion)
+	}
+
+	history := ai.ProgressHistory()
+	if !slices.Equal(history, []int{1, 1, 3}) {
+		t.Errorf("progress history %v, want [1 1 3]", history)
+	}
+	total := len(config.Categories)
+	for _, ascii := range []bool{false, true} {
+		line := sparkline(history, total, ascii)
+		if got := utf8.RuneCountInString(line); got != len(turns) {
+			t.Errorf("ascii %t: sparkline %q has %d characters, want %d", ascii, line, got, len(turns))
+		}
+	}
+	if got := sparkline(history, total, true); got != "113" {
+		t.Errorf("ASCII sparkline %q, want 113", got)
+	}
+}
+
+func TestShowedRecordsTheDisclosure(t *testing.T) {
+	// We are A holding Miss Scarlett, Candlestick and Kitchen.
+	s := newDetectiveSession(NewScriptedInput(
+		"3", "A", "B", "C", "1", "1", "7", "13", "done",
+		"showed B Candlestick", "showed 3 Kitchen", "showed B Rope",
+		"quit",
+	), C)
+	out := captureStdout(t, s.run)
+
+	if got := s.brain.OpponentKnows("B"); !slices.Equal(got, []string{"Candlestick"}) {
+		t.Errorf("B is known to have seen %v, want [Candlestick]", got)
+	}
+	if got := s.brain.OpponentKnows("C"); !slices.Equal(got, []string{"Kitchen"}) {
+		t.Errorf("C is known to have seen %v, want [Kitchen]", got)
+	}
+	if !s.brain.disclosed["B"]["Candlestick"] || s.brain.disclosed["B"]["Rope"] {
+		t.Errorf("disclosures to B: %v, want only the Candlestick", s.brain.disclosed["B"])
+	}
+	if !strings.Contains(out, "Rope is not in your hand") {
+		t.Error("showing a card we do not hold was not refused")
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// recordingListener writes down every turn it is told about as one line of
+// public information: who suggested or accused what, who passed and who
+// disproved. Cards shown privately are left out.
+type recordingListener struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recordingListener) Turn(turn int, out TurnOutcome) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, describeTurn(turn, out))
+}
+
+func (r *recordingListener) Events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.events...)
+}
+
+func describeTurn(turn int, out TurnOutcome) string {
+	cards := func(m map[string]string) string {
+		var list []string
+		for _, cat := range config.CategoryNames() {
+			list = append(list, m[cat])
+		}
+		return strings.Join(list, ", ")
+	}
+	player := out.Player.Name()
+	switch {
+	case out.Accusation != nil:
+		return fmt.Sprintf("%d %s accuses %s: correct=%t", turn, player, cards(out.Accusation), out.Correct)
+	case out.Suggestion == nil:
+		return fmt.Sprintf("%d %s passes", turn, player)
+	}
+	disprover := out.Disprover
+	if disprover == "" {
+		disprover = "nobody"
+	}
+	return fmt.Sprintf("%d %s suggests %s; passed [%s]; disproved by %s", turn, player, cards(out.Suggestion), strings.Join(out.Passed, ", "), disprover)
+}
+
+// newSeededGame deals an all-AI game whose every choice comes from seed.
+func newSeededGame(t *testing.T, numAI int, seed int64) *Game {
+	t.Helper()
+	g, err := NewGameWithRng(config, 0, numAI, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Deal(); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// playSeededGame plays a seeded game to its end, reporting its turns to l.
+func playSeededGame(t *testing.T, numAI int, seed int64, l TurnListener) *Game {
+	t.Helper()
+	g := newSeededGame(t, numAI, seed)
+	g.Subscribe(l)
+	g.Play(defaultTurnLimit)
+	return g
+}
+
+func TestGoldenEventStream(t *testing.T) {
+	rec := &recordingListener{}
+	g := playSeededGame(t, 4, 42, rec)
+	if !g.Finished() {
+		t.Fatalf("golden game did not finish within %d turns", defaultTurnLimit)
+	}
+	got := rec.Events()
+
+	path := filepath.Join("testdata", "golden_events.txt")
+	if *update {
+		if err := os.WriteFile(path, []byte(strings.Join(got, "\n")+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -run TestGoldenEventStream -update to create it)", err)
+	}
+	want := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	for i := 0; i < max(len(got), len(want)); i++ {
+		var g, w string
+		if i < len(got) {
+			g = got[i]
+		}
+		if i < len(want) {
+			w = want[i]
+		}
+		if g != w {
+			t.Fatalf("event %d differs from %s (of %d recorded, %d expected):\n got: %q\nwant: %q", i+1, path, len(got), len(want), g, w)
+		}
+	}
+}
+
+func TestUnsubscribedListenerHearsNoMoreTurns(t *testing.T) {
+	g := newSeededGame(t, 3, 7)
+	rec := &recordingListener{}
+	g.Subscribe(rec)
+	g.PlayTurn()
+	g.PlayTurn()
+	if !g.Unsubscribe(rec) {
+		t.Fatal("Unsubscribe did not find the listener")
+	}
+	for i := 0; i < 5; i++ {
+		g.PlayTurn()
+	}
+	if got := len(rec.Events()); got != 2 {
+		t.Errorf("listener heard %d turns, want the 2 played while subscribed", got)
+	}
+	if g.Unsubscribe(rec) {
+		t.Error("a second Unsubscribe reported the listener as still subscribed")
+	}
+}
+
+func TestSubscribingTwiceDeliversOnce(t *testing.T) {
+	g := newSeededGame(t, 3, 7)
+	rec := &recordingListener{}
+	g.Subscribe(rec)
+	g.Subscribe(rec)
+	g.PlayTurn()
+	if got := rec.Events(); len(got) != 1 {
+		t.Errorf("listener heard %q, want one turn", got)
+	}
+}
+
+// Run with -race: listeners may come and go from other goroutines, such as
+// HTTP handlers, while the game plays.
+func TestSubscribeWhilePlaying(t *testing.T) {
+	g := newSeededGame(t, 4, 11)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := &recordingListener{}
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				g.Subscribe(rec)
+				g.Unsubscribe(rec)
+			}
+		}()
+	}
+	steady := &recordingListener{}
+	g.Subscribe(steady)
+	turns := 0
+	for ; turns < 30 && !g.Finished(); turns++ {
+		g.PlayTurn()
+	}
+	close(stop)
+	wg.Wait()
+	if got := len(steady.Events()); got != turns {
+		t.Errorf("steady listener heard %d turns, want %d", got, turns)
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"maps"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestOpenHandsTeachEveryBrainTheShownCard(t *testing.T) {
+	g := newSeededGame(t, 4, 7)
+	g.OpenHands = true
+	reveals := 0
+	for turn := 0; turn < 20 && !g.Finished(); turn++ {
+		out := g.PlayTurn()
+		if out.RevealedCard == "" {
+			continue
+		}
+		reveals++
+		for _, p := range g.Players {
+			ai := p.(*AdvancedAIBrain)
+			if got := ai.knowledge[out.RevealedCard][out.Disprover]; got != StatusYes {
+				t.Errorf("turn %d: %s has %s with %s as %s, want Yes", turn, ai.Name(), out.RevealedCard, out.Disprover, got)
+			}
+		}
+	}
+	if reveals == 0 {
+		t.Fatal("no card was shown")
+	}
+}
+
+func TestHumanCanAskTheCoPilotForAdvice(t *testing.T) {
+	g, err := NewGameWithRng(config, 1, 2, rand.New(rand.NewSource(3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var human *HumanPlayer
+	for _, p := range g.Players {
+		if h, ok := p.(*HumanPlayer); ok {
+			human = h
+		}
+	}
+	// Decline to accuse, ask for advice, then pass.
+	human.SetController(NewLinerController(NewScriptedInput("n", "advice", "pass")))
+	human.EnableAssist()
+
+	out := captureStdout(t, func() {
+		if err := g.Deal(); err != nil {
+			t.Fatal(err)
+		}
+		for !g.Finished() && g.turn < len(g.Players) {
+			g.PlayTurn()
+		}
+	})
+	if err := human.Err(); err != nil {
+		t.Fatalf("the human left the game: %v", err)
+	}
+	_, advice, found := strings.Cut(out, "Co-pilot suggests: ")
+	advice, _, _ = strings.Cut(advice, "\n")
+	if !found {
+		t.Fatalf("no advice was given; output:\n%s", out)
+	}
+	for _, cat := range config.CategoryNames() {
+		named := 0
+		for _, card := range config.CardsOf(cat) {
+			if strings.Contains(advice, card) {
+				named++
+			}
+		}
+		if named != 1 {
+			t.Errorf("%q names %d %s, want 1", advice, named, cat)
+		}
+	}
+	if !strings.Contains(out, "Co-pilot's best guess at the solution: ") {
+		t.Error("the co-pilot gave no best guess")
+	}
+}
+
+// reverseRng draws the first option and shuffles by reversing.
+type reverseRng struct{}
+
+func (reverseRng) Intn(int) int { return 0 }
+func (reverseRng) Shuffle(n int, swap func(i, j int)) {
+	for i := 0; i < n/2; i++ {
+		swap(i, n-1-i)
+	}
+}
+
+func TestDealFollowsTheRngShuffle(t *testing.T) {
+	last := func(cards []string) string { return cards[len(cards)-1] }
+	reversed := slices.Clone(config.Suspects[:3])
+	slices.Reverse(reversed)
+	for _, tc := range []struct {
+		name     string
+		rng      Rng
+		seating  []string
+		solution func([]string) string
+	}{
+		// The solution is the last card of each category left in the deck.
+		{"unshuffled", firstRng{}, config.Suspects[:3], last},
+		{"reversed", reverseRng{}, reversed, func(cards []string) string { return cards[0] }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := NewGameWithRng(config, 0, 3, tc.rng)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := g.Deal(); err != nil {
+				t.Fatal(err)
+			}
+			var seating []string
+			for _, p := range g.Players {
+				seating = append(seating, p.Name())
+			}
+			if !slices.Equal(seating, tc.seating) {
+				t.Errorf("seating = %v, want %v", seating, tc.seating)
+			}
+			for _, cat := range config.CategoryNames() {
+				if got, want := g.Solution[cat], tc.solution(config.CardsOf(cat)); got != want {
+					t.Errorf("%s solution = %s, want %s", cat, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestQuietSimulationPrintsOnlyMilestones(t *testing.T) {
+	savedLevel, savedOut := log.GetLevel(), log.Out
+	t.Cleanup(func() {
+		log.SetLevel(savedLevel)
+		log.SetOutput(savedOut)
+	})
+
+	play := func(quiet bool) (stdout, logged string) {
+		var logs bytes.Buffer
+		log.SetOutput(&logs)
+		log.SetLevel(logrus.InfoLevel)
+		g := newSeededGame(t, 4, 42)
+		stdout = captureStdout(t, func() {
+			if _, err := runSimulationLoop(g, quiet); err != nil {
+				t.Fatal(err)
+			}
+		})
+		return stdout, logs.String()
+	}
+
+	loud, _ := play(false)
+	if !strings.Contains(loud, " suggests: ") {
+		t.Fatalf("the narrated game has no suggestions:\n%s", loud)
+	}
+	out, logged := play(true)
+	for _, routine := range []string{" suggests: ", "shows a card", "No player could show a card", "--- Turn "} {
+		if strings.Contains(out, routine) {
+			t.Errorf("quiet output contains %q:\n%s", routine, out)
+		}
+	}
+	for _, milestone := range []string{" accuses! ", "--- GAME OVER ---"} {
+		if !strings.Contains(out, milestone) {
+			t.Errorf("quiet output lacks %q:\n%s", milestone, out)
+		}
+	}
+	if logged != "" {
+		t.Errorf("quiet game logged:\n%s", logged)
+	}
+}
+
+func TestRedealResetsEveryBrain(t *testing.T) {
+	changed := 0
+	for seed := int64(1); seed <= 20; seed++ {
+		g := newSeededGame(t, 4, seed)
+		before := maps.Clone(g.Solution)
+		for range 8 {
+			g.PlayTurn()
+		}
+		if err := g.Redeal(); err != nil {
+			t.Fatalf("seed %d: Redeal: %v", seed, err)
+		}
+		if !maps.Equal(before, g.Solution) {
+			changed++
+		}
+
+		names := make([]string, len(g.Players))
+		for i, p := range g.Players {
+			names[i] = p.Name()
+		}
+		for _, p := range g.Players {
+			ai := p.(*AdvancedAIBrain)
+			fresh := NewAdvancedAIBrain()
+			fresh.Setup(config, names, ai.Name())
+			fresh.ReceiveHand(g.Hands[ai.Name()])
+			if ai.turnsSeen != 0 {
+				t.Errorf("seed %d: %s has seen %d turns since the redeal", seed, ai.Name(), ai.turnsSeen)
+			}
+			if !maps.EqualFunc(ai.knowledge, fresh.knowledge, maps.Equal) {
+				t.Errorf("seed %d: %s kept knowledge from the previous deal", seed, ai.Name())
+			}
+		}
+	}
+	if changed < 15 {
+		t.Errorf("the solution changed in only %d of 20 redeals", changed)
+	}
+}
+
+// tracedGame plays a seeded all-AI game and returns its Rng trace.
+func tracedGame(t *testing.T, seed int64) string {
+	t.Helper()
+	var trace bytes.Buffer
+	g, err := NewGameWithRng(config, 0, 4, NewTracingRng(rand.New(rand.NewSource(seed)), &trace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Deal(); err != nil {
+		t.Fatal(err)
+	}
+	g.Play(defaultTurnLimit)
+	return trace.String()
+}
+
+func TestSameSeedGivesTheSameRngTrace(t *testing.T) {
+	first, second := tracedGame(t, 9), tracedGame(t, 9)
+	if first == "" {
+		t.Fatal("the game drew nothing from its Rng")
+	}
+	if first != second {
+		t.Error("two runs with the same seed drew different random numbers")
+	}
+	if tracedGame(t, 10) == first {
+		t.Error("runs with different seeds gave the same trace")
+	}
+}
+
+func TestEliminatedPlayersStillDisprove(t *testing.T) {
+	g := newSeededGame(t, 3, 1)
+	suggester, out := g.Players[0], g.Players[1]
+	g.eliminated[out.Name()] = true
+
+	// Only the eliminated player can show their card; the other slots are
+	// the solution, which nobody holds.
+	card := g.Hands[out.Name()][0]
+	suggestion := maps.Clone(g.Solution)
+	suggestion[g.Config.CardToType[card]] = card
+	disprover, shown := g.HandleSuggestion(suggester, suggestion)
+	if disprover != out.Name() || shown != card {
+		t.Errorf("HandleSuggestion = %s, %s; want the eliminated %s to show %s", disprover, shown, out.Name(), card)
+	}
+}
+
+func TestQuietlyRestoresTheLogLevel(t *testing.T) {
+	saved := log.GetLevel()
+	t.Cleanup(func() { log.SetLevel(saved) })
+
+	for _, level := range []logrus.Level{logrus.DebugLevel, logrus.ErrorLevel} {
+		log.SetLevel(level)
+		var inside logrus.Level
+		quietly(func() { inside = log.GetLevel() })
+		if want := min(level, logrus.WarnLevel); inside != want {
+			t.Errorf("from %s: level inside quietly is %s, want %s", level, inside, want)
+		}
+		if got := log.GetLevel(); got != level {
+			t.Errorf("from %s: level after quietly is %s", level, got)
+		}
+	}
+}
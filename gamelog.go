@@ -0,0 +1,197 @@
+// gamelog.go
+// Plain-text game logs that can be fed back into detective mode.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TurnRecord is one logged turn as seen from a single seat. The log format is
+// one turn per line:
+//
+//	suggester | suspect, weapon, room | disprover | shown
+//
+// where disprover is "none" if nobody could disprove and shown is left empty
+// unless the recording seat saw (or showed) the card. Lines starting with '#' are comments.
+type TurnRecord struct {
+	Suggester  string            `json:"suggester"`
+	Suggestion map[string]string `json:"suggestion"`
+	Disprover  string            `json:"disprover,omitempty"`
+	Shown      string            `json:"shown,omitempty"`
+}
+
+func (r TurnRecord) String() string {
+	disprover := r.Disprover
+	if disprover == "" {
+		disprover = "none"
+	}
+	var cards []string
+	for _, cat := range config.CategoryNames() {
+		cards = append(cards, r.Suggestion[cat])
+	}
+	line := fmt.Sprintf("%s | %s | %s", r.Suggester, strings.Join(cards, ", "), disprover)
+	if r.Shown != "" {
+		line += " | " + r.Shown
+	}
+	return line
+}
+
+// writeTurnLog writes a seat's view of a game: a commented header describing
+// the table and the seat's hand, followed by one line per turn.
+func writeTurnLog(w io.Writer, players []string, me string, hand []string, turns []TurnRecord) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# players: %s\n", strings.Join(players, ", "))
+	fmt.Fprintf(bw, "# me: %s\n", me)
+	fmt.Fprintf(bw, "# hand: %s\n", strings.Join(hand, ", "))
+	for _, t := range turns {
+		fmt.Fprintln(bw, t.String())
+	}
+	return bw.Flush()
+}
+
+// readTurnLog parses a turn log, as written by writeTurnLog or kept by hand,
+// for a table of the given players. Player and card names are matched
+// case-insensitively and cards may be given by number. Every problem is
+// reported with its line number; if there are any, no turns are returned.
+// lines holds the line number of each turn.
+func readTurnLog(r io.Reader, players []string) (turns []TurnRecord, lines []int, err error) {
+	player := func(name string) string {
+		for _, p := range players {
+			if strings.EqualFold(p, name) {
+				return p
+			}
+		}
+		return ""
+	}
+	var errs []error
+	scanner := bufio.NewScanner(r)
+	for num := 1; scanner.Scan(); num++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		t, err := parseTurnLine(text, player)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", num, err))
+			continue
+		}
+		turns = append(turns, t)
+		lines = append(lines, num)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	return turns, lines, nil
+}
+
+// parseTurnLine parses one "suggester | cards | disprover | shown" line;
+// player resolves a player name, returning "" if there is no such player.
+func parseTurnLine(text string, player func(string) string) (TurnRecord, error) {
+	fields := strings.Split(text, "|")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	if len(fields) < 3 || len(fields) > 4 {
+		return TurnRecord{}, errors.New("want \"suggester | cards | disprover\" and optionally \"| shown\"")
+	}
+	t := TurnRecord{Suggester: player(fields[0]), Suggestion: make(map[string]string)}
+	if t.Suggester == "" {
+		return t, fmt.Errorf("unknown player %q", fields[0])
+	}
+	for _, name := range strings.Split(fields[1], ",") {
+		card := lookupCard(name)
+		if card == "" {
+			return t, fmt.Errorf("unknown card %q", strings.TrimSpace(name))
+		}
+		cat := config.CardToType[card]
+		if _, dup := t.Suggestion[cat]; dup {
+			return t, fmt.Errorf("two %s suggested", cat)
+		}
+		t.Suggestion[cat] = card
+	}
+	if !isCompleteSuggestion(config, t.Suggestion) {
+		return t, fmt.Errorf("a suggestion needs one card from each of: %s", strings.Join(config.CategoryNames(), ", "))
+	}
+	if !strings.EqualFold(fields[2], "none") && fields[2] != "" {
+		if t.Disprover = player(fields[2]); t.Disprover == "" {
+			return t, fmt.Errorf("unknown player %q", fields[2])
+		}
+		if t.Disprover == t.Suggester {
+			return t, fmt.Errorf("%s cannot disprove their own suggestion", t.Suggester)
+		}
+	}
+	if len(fields) == 4 && fields[3] != "" && fields[3] != "?" {
+		if t.Shown = lookupCard(fields[3]); t.Shown == "" {
+			return t, fmt.Errorf("unknown card %q", fields[3])
+		}
+		if t.Disprover == "" {
+			return t, errors.New("a card was shown but nobody disproved")
+		}
+		if t.Suggestion[config.CardToType[t.Shown]] != t.Shown {
+			return t, fmt.Errorf("%s was shown but not suggested", t.Shown)
+		}
+	}
+	return t, nil
+}
+
+// runGenerateLog plays an all-AI game and records it from one seat's point of
+// view, producing a log a detective-mode user could have kept at the table.
+func runGenerateLog(seat int, path string, numAI int, openHands bool) error {
+	g, err := NewGame(config, 0, numAI)
+	if err != nil {
+		return err
+	}
+	if seat < 1 || seat > numAI {
+		return fmt.Errorf("seat must be between 1 and %d", numAI)
+	}
+	if log.GetLevel() > logrus.WarnLevel {
+		log.SetLevel(logrus.WarnLevel)
+	}
+
+	g.OpenHands = openHands
+	if err := g.Deal(); err != nil {
+		return err
+	}
+	me := g.Players[seat-1].(*AdvancedAIBrain)
+
+	for !g.Finished() && g.turn < defaultTurnLimit {
+		g.PlayTurn()
+	}
+	var turns []TurnRecord
+	Replay(g.History(), TurnListenerFunc(func(turn int, out TurnOutcome) {
+		if out.Suggestion == nil {
+			return
+		}
+		rec := TurnRecord{Suggester: out.Player.Name(), Suggestion: out.Suggestion, Disprover: out.Disprover}
+		if out.Player.Name() == me.Name() || out.Disprover == me.Name() || g.OpenHands {
+			rec.Shown = out.RevealedCard
+		}
+		turns = append(turns, rec)
+	}))
+
+	var players []string
+	for _, p := range g.Players {
+		players = append(players, p.Name())
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := writeTurnLog(f, players, me.Name(), me.Hand(), turns); err != nil {
+		return err
+	}
+	C.Info.Printf("Wrote %d turns from %s's perspective to %s.\n", len(turns), colorizeCard(me.Name()), path)
+	return nil
+}
@@ -0,0 +1,37 @@
+// input.go
+// Where typed lines come from: the terminal, or a script standing in for it.
+
+package main
+
+import "io"
+
+// InputSource reads the user's answers. *liner.State satisfies it.
+type InputSource interface {
+	// Prompt shows msg and returns the next line. It returns io.EOF once
+	// input ends, and liner.ErrPromptAborted if the user pressed Ctrl-C.
+	Prompt(msg string) (string, error)
+	// AppendHistory records an accepted answer for recall.
+	AppendHistory(item string)
+}
+
+// ScriptedInput answers prompts from a fixed list of lines, so a session can
+// be driven without a terminal. Once the lines run out it returns io.EOF.
+type ScriptedInput struct {
+	lines []string
+}
+
+// NewScriptedInput queues lines to be returned in order.
+func NewScriptedInput(lines ...string) *ScriptedInput {
+	return &ScriptedInput{lines: lines}
+}
+
+func (s *ScriptedInput) Prompt(msg string) (string, error) {
+	if len(s.lines) == 0 {
+		return "", io.EOF
+	}
+	line := s.lines[0]
+	s.lines = s.lines[1:]
+	return line, nil
+}
+
+func (s *ScriptedInput) AppendHistory(item string) {}
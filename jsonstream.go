@@ -0,0 +1,183 @@
+// jsonstream.go
+// Narrates a simulation as newline-delimited JSON for external tools.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+// JSONStreamRenderer writes one JSON object per line for each game event.
+// Every object has a "type" field naming the event. Suggestions and
+// solutions are objects keyed by category name, e.g. {"suspects": "..."}.
+type JSONStreamRenderer struct {
+	enc       *json.Encoder
+	openHands bool // Shown cards are only public with open hands.
+
+	// ShowPrivate adds a card_shown_privately event naming the card only the
+	// suggester saw. It goes to the stream alone, never to the players.
+	ShowPrivate bool
+}
+
+func NewJSONStreamRenderer(w io.Writer) *JSONStreamRenderer {
+	return &JSONStreamRenderer{enc: json.NewEncoder(w)}
+}
+
+type gameStartEvent struct {
+	Type       string   `json:"type"` // "game_start"
+	Players    []string `json:"players"`
+	Categories []string `json:"categories"`
+	OpenHands  bool     `json:"open_hands,omitempty"`
+}
+
+type turnStartEvent struct {
+	Type   string `json:"type"` // "turn_start"
+	Turn   int    `json:"turn"`
+	Player string `json:"player"`
+}
+
+type accusationEvent struct {
+	Type       string            `json:"type"` // "accusation"
+	Turn       int               `json:"turn"`
+	Player     string            `json:"player"`
+	Cards      map[string]string `json:"cards"`
+	Correct    bool              `json:"correct"`
+	Eliminated bool              `json:"eliminated,omitempty"`
+}
+
+type suggestionMadeEvent struct {
+	Type       string            `json:"type"` // "suggestion"
+	Turn       int               `json:"turn"`
+	Player     string            `json:"player"`
+	Suggestion map[string]string `json:"suggestion"`
+	Strategy   string            `json:"strategy,omitempty"`
+}
+
+type disprovalEvent struct {
+	Type      string   `json:"type"` // "disproval"
+	Turn      int      `json:"turn"`
+	Passed    []string `json:"passed"`
+	Disprover string   `json:"disprover,omitempty"` // Empty if nobody could disprove.
+	Shown     string   `json:"shown,omitempty"`     // Only with open hands.
+}
+
+type solutionFoundEvent struct {
+	Type     string            `json:"type"` // "solution_found"
+	Turn     int               `json:"turn"`
+	Player   string            `json:"player"`
+	Solution map[string]string `json:"solution"`
+}
+
+type cardShownPrivatelyEvent struct {
+	Type       string `json:"type"` // "card_shown_privately"
+	Turn       int    `json:"turn"`
+	FromPlayer string `json:"from_player"`
+	ToPlayer   string `json:"to_player"`
+	Card       string `json:"card"`
+}
+
+type gameOverEvent struct {
+	Type     string            `json:"type"` // "game_over", "stalemate", "turn_limit_reached" or "abandoned"
+	Turns    int               `json:"turns"`
+	Winner   string            `json:"winner,omitempty"`
+	Team     []string          `json:"team,omitempty"` // The winner and their teammates.
+	Solution map[string]string `json:"solution"`
+}
+
+func (r *JSONStreamRenderer) emit(event interface{}) {
+	if err := r.enc.Encode(event); err != nil {
+		log.Errorf("Could not write event: %v", err)
+	}
+}
+
+// GameStart announces the table.
+func (r *JSONStreamRenderer) GameStart(g *Game) {
+	var players []string
+	for _, p := range g.Players {
+		players = append(players, p.Name())
+	}
+	r.openHands = g.OpenHands
+	r.emit(gameStartEvent{Type: "game_start", Players: players, Categories: g.Config.CategoryNames(), OpenHands: g.OpenHands})
+}
+
+// Turn reports one played turn, numbered from 1.
+func (r *JSONStreamRenderer) Turn(turn int, out TurnOutcome) {
+	player := out.Player.Name()
+	r.emit(turnStartEvent{Type: "turn_start", Turn: turn, Player: player})
+	if out.Accusation != nil {
+		r.emit(accusationEvent{Type: "accusation", Turn: turn, Player: player, Cards: out.Accusation, Correct: out.Correct, Eliminated: out.Eliminated})
+		return
+	}
+	if out.Suggestion == nil {
+		return
+	}
+	r.emit(suggestionMadeEvent{Type: "suggestion", Turn: turn, Player: player, Suggestion: out.Suggestion, Strategy: out.Strategy})
+	ev := disprovalEvent{Type: "disproval", Turn: turn, Passed: out.Passed, Disprover: out.Disprover}
+	if ev.Passed == nil {
+		ev.Passed = []string{}
+	}
+	if r.openHands {
+		ev.Shown = out.RevealedCard
+	}
+	r.emit(ev)
+	if r.ShowPrivate && !r.openHands && out.RevealedCard != "" {
+		r.emit(cardShownPrivatelyEvent{Type: "card_shown_privately", Turn: turn, FromPlayer: out.Disprover, ToPlayer: player, Card: out.RevealedCard})
+	}
+}
+
+// GameOver reports the result after the given number of turns,
+// distinguishing a win from a game given up as stuck or too long, or left by
+// a human player.
+func (r *JSONStreamRenderer) GameOver(g *Game, turns int) {
+	ev := gameOverEvent{Type: "game_over", Turns: turns, Winner: g.Winner(), Solution: g.Solution}
+	if team := g.WinningTeam(); len(team) > 1 {
+		ev.Team = team
+	}
+	switch {
+	case g.Err() != nil:
+		ev.Type = "abandoned"
+	case g.Stalemate():
+		ev.Type = "stalemate"
+	case !g.Finished():
+		ev.Type = "turn_limit_reached"
+	}
+	r.emit(ev)
+}
+
+// streamSimulation plays a dealt game, narrating it to r instead of the
+// console, and returns the winner. Like runSimulationLoop, it stops with
+// Game.Err if a human leaves.
+func streamSimulation(g *Game, r *JSONStreamRenderer) (string, error) {
+	// Deduction narration would interleave with the stream on a shared terminal.
+	if log.GetLevel() > logrus.WarnLevel {
+		log.SetLevel(logrus.WarnLevel)
+	}
+	r.GameStart(g)
+	// Solutions are found mid-turn; report them after the turn that found them.
+	var found []solutionFoundEvent
+	for _, p := range g.Players {
+		if ai, ok := p.(*AdvancedAIBrain); ok {
+			name := ai.Name()
+			ai.OnSolutionFound = func(solution map[string]string) {
+				found = append(found, solutionFoundEvent{Type: "solution_found", Turn: g.turn + 1, Player: name, Solution: solution})
+			}
+		}
+	}
+	g.Subscribe(r)
+	defer g.Unsubscribe(r)
+	for !g.Finished() && !g.Stalemate() && g.turn < g.TurnLimit() {
+		g.PlayTurn()
+		if g.Err() != nil {
+			break
+		}
+		for _, ev := range found {
+			r.emit(ev)
+		}
+		found = nil
+	}
+	r.GameOver(g, len(g.History()))
+	return g.Winner(), g.Err()
+}
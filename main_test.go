@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/sirupsen/logrus"
+)
+
+func TestMain(m *testing.M) {
+	// The brains narrate every deduction at info level.
+	log.SetLevel(logrus.WarnLevel)
+	cfg, err := Preset("classic")
+	if err != nil {
+		panic(err)
+	}
+	config = *cfg
+	os.Exit(m.Run())
+}
+
+// threePlayerBrain returns a fresh brain seated at a table of Me, Left and
+// Right.
+func threePlayerBrain() *AdvancedAIBrain {
+	ai := NewAdvancedAIBrain()
+	ai.Setup(config, []string{"Me", "Left", "Right"}, "Me")
+	return ai
+}
+
+// firstCards returns a suggestion of each category's first card.
+func firstCards() map[string]string {
+	suggestion := make(map[string]string)
+	for _, cat := range config.CategoryNames() {
+		suggestion[cat] = config.CardsOf(cat)[0]
+	}
+	return suggestion
+}
+
+// captureStdout returns everything f prints to standard output, including
+// what the themes print through color.Output.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, savedColor := os.Stdout, color.Output
+	os.Stdout, color.Output = w, w
+	defer func() { os.Stdout, color.Output = saved, savedColor }()
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	f()
+	w.Close()
+	return <-out
+}
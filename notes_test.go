@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestNoColorHighlightUsesMarkersOnly(t *testing.T) {
+	saved := color.NoColor
+	t.Cleanup(func() { color.NoColor = saved })
+
+	ai := threePlayerBrain()
+	before := ai.deepCopyKnowledge()
+	suggestion := firstCards()
+	ai.ProcessTurnInfo("Me", "Left", suggestion["weapons"], suggestion)
+	changed := changedCells(before, ai.knowledge)
+	if len(changed) == 0 {
+		t.Fatal("the logged turn changed no cells")
+	}
+
+	color.NoColor = true
+	out := captureStdout(t, func() { ai.RenderNotes(C, changed) })
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("no-color notes contain ANSI escapes:\n%q", out)
+	}
+	if got := strings.Count(out, "*"); got != len(changed) {
+		t.Errorf("%d cells are marked, want the %d that changed:\n%s", got, len(changed), out)
+	}
+
+	color.NoColor = false
+	if out := captureStdout(t, func() { ai.RenderNotes(C, changed) }); !strings.Contains(out, "\x1b[") {
+		t.Error("colored notes contain no ANSI escapes")
+	}
+}
+
+func TestNotesSVGHasACellPerGridSquare(t *testing.T) {
+	ai := threePlayerBrain()
+	ai.ReceiveHand(config.AllCards[:6])
+	var buf bytes.Buffer
+	if err := ExportNotesSVG(&buf, ai); err != nil {
+		t.Fatalf("ExportNotesSVG: %v", err)
+	}
+
+	counts := make(map[string]int)
+	dec := xml.NewDecoder(&buf)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("the SVG is not well-formed XML: %v", err)
+		}
+		if el, ok := tok.(xml.StartElement); ok {
+			counts[el.Name.Local]++
+		}
+	}
+	cards, locations := len(config.AllCards), len(ai.players)+1
+	// A rect per cell; text for the title, each location header, each card
+	// name and each cell's glyph.
+	if want := cards * locations; counts["rect"] != want {
+		t.Errorf("%d rect elements, want %d", counts["rect"], want)
+	}
+	if want := 1 + locations + cards*(1+locations); counts["text"] != want {
+		t.Errorf("%d text elements, want %d", counts["text"], want)
+	}
+	if counts["svg"] != 1 {
+		t.Errorf("%d svg elements, want 1", counts["svg"])
+	}
+}
+
+func TestImportKnowledgeDropsTheOldReasoning(t *testing.T) {
+	suspects, weapons, rooms := config.CardsOf("suspects"), config.CardsOf("weapons"), config.CardsOf("rooms")
+	source := threePlayerBrain()
+	source.ReceiveHand([]string{suspects[0], weapons[0], rooms[0]})
+	data, err := source.ExportKnowledge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ai := threePlayerBrain()
+	ai.ReceiveHand([]string{suspects[0], weapons[0], rooms[0]})
+	// Left is shown a card we cannot see, leaving a mystery, and we are shown
+	// the Colonel, leaving a recorded placement.
+	ai.ProcessTurnInfo("Left", "Right", "", map[string]string{"suspects": suspects[2], "weapons": weapons[2], "rooms": rooms[2]})
+	ai.ProcessTurnInfo("Me", "Left", suspects[1], map[string]string{"suspects": suspects[1], "weapons": weapons[1], "rooms": rooms[1]})
+	if len(ai.unresolvedSuggestions) == 0 || len(ai.Explain(suspects[1])) == 0 {
+		t.Fatal("the set-up turns left no mystery or no reasoning")
+	}
+
+	if err := ai.ImportKnowledge(data); err != nil {
+		t.Fatalf("ImportKnowledge: %v", err)
+	}
+	if got := ai.knowledge[suspects[1]]["Left"]; got != StatusMaybe {
+		t.Errorf("the Colonel is %s with Left after the import, want Maybe", got)
+	}
+	if chain := ai.Explain(suspects[1]); len(chain) != 0 {
+		t.Errorf("the old placement still explains the Colonel: %v", chain)
+	}
+	if r := ai.Reasoning(); len(r) != 0 {
+		t.Errorf("reasoning from before the import survived: %v", r)
+	}
+	if len(ai.unresolvedSuggestions) != 0 {
+		t.Errorf("%d mysteries from the old grid survived the import", len(ai.unresolvedSuggestions))
+	}
+}
@@ -0,0 +1,93 @@
+// presets.go
+// Built-in card sets that can be chosen by name instead of a config file.
+
+package main
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// presets maps a preset name to its suspects, weapons and rooms.
+var presets = map[string][3][]string{
+	// The standard board game, identical to default_config.json.
+	"classic": {
+		{"Miss Scarlett", "Colonel Mustard", "Mrs. White", "Mr. Green", "Mrs. Peacock", "Professor Plum"},
+		{"Candlestick", "Dagger", "Lead Pipe", "Revolver", "Rope", "Wrench"},
+		{"Kitchen", "Ballroom", "Conservatory", "Dining Room", "Billiard Room", "Library", "Lounge", "Hall", "Study"},
+	},
+	// A reduced deck for fast rounds, demos and teaching. Seats up to 4.
+	"quick": {
+		{"Miss Scarlett", "Colonel Mustard", "Mrs. White", "Mr. Green"},
+		{"Candlestick", "Dagger", "Rope", "Wrench"},
+		{"Kitchen", "Ballroom", "Library", "Lounge", "Study"},
+	},
+}
+
+// Preset returns a fresh, validated copy of a built-in config.
+func Preset(name string) (*GameConfig, error) {
+	lists, ok := presets[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: no preset named %q", ErrConfigNotFound, name)
+	}
+	cfg := &GameConfig{
+		Suspects: append([]string{}, lists[0]...),
+		Weapons:  append([]string{}, lists[1]...),
+		Rooms:    append([]string{}, lists[2]...),
+	}
+	cfg.buildIndex()
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("preset %s: %w", name, err)
+	}
+	return cfg, nil
+}
+
+// PresetNames lists the built-in presets in sorted order.
+func PresetNames() []string {
+	var names []string
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultConfigPath is the config file read when -config is not given.
+const defaultConfigPath = "default_config.json"
+
+// embeddedDefaultConfig is default_config.json as it was at build time, so
+// the binary works from any directory.
+//
+//go:embed default_config.json
+var embeddedDefaultConfig []byte
+
+// LoadDefaultConfig parses the embedded default config.
+func LoadDefaultConfig() (GameConfig, error) {
+	return parseConfig(embeddedDefaultConfig, "embedded "+defaultConfigPath)
+}
+
+// selectConfig makes the named preset, or else the config file at that path,
+// the global config.
+func selectConfig(nameOrPath string) error {
+	if _, ok := presets[nameOrPath]; ok {
+		cfg, err := Preset(nameOrPath)
+		if err != nil {
+			return err
+		}
+		config = *cfg
+		return nil
+	}
+	err := loadConfig(nameOrPath)
+	if nameOrPath == defaultConfigPath && errors.Is(err, ErrConfigNotFound) {
+		// Not run from the repository root; the built-in copy will do.
+		cfg, err := LoadDefaultConfig()
+		if err != nil {
+			return err
+		}
+		config = cfg
+		return nil
+	}
+	return err
+}
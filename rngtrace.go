@@ -0,0 +1,50 @@
+// rngtrace.go
+// A debugging Rng that records every random draw, so two runs that should be
+// identical can be diffed to find where they diverge.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// TracingRng wraps another Rng and writes one numbered line per call.
+// Shuffles record the swaps they made. It is safe for concurrent use, though
+// traces of concurrent runs interleave nondeterministically.
+type TracingRng struct {
+	inner Rng
+	mu    sync.Mutex
+	w     *bufio.Writer
+	seq   int
+}
+
+func NewTracingRng(inner Rng, w io.Writer) *TracingRng {
+	return &TracingRng{inner: inner, w: bufio.NewWriter(w)}
+}
+
+func (t *TracingRng) Intn(n int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	v := t.inner.Intn(n)
+	t.seq++
+	fmt.Fprintf(t.w, "%d Intn(%d) = %d\n", t.seq, n, v)
+	t.w.Flush()
+	return v
+}
+
+func (t *TracingRng) Shuffle(n int, swap func(i, j int)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var swaps []string
+	t.inner.Shuffle(n, func(i, j int) {
+		swaps = append(swaps, fmt.Sprintf("%d<>%d", i, j))
+		swap(i, j)
+	})
+	t.seq++
+	fmt.Fprintf(t.w, "%d Shuffle(%d) %s\n", t.seq, n, strings.Join(swaps, " "))
+	t.w.Flush()
+}
@@ -0,0 +1,171 @@
+// rollout.go
+// Endgame planning: play the rest of the game out many times against deals
+// that fit the notes, to see which suggestion forces the solution soonest.
+
+package main
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+)
+
+// rolloutTurnCap stops a rollout that has not solved the game; it then
+// counts as taking this many turns.
+const rolloutTurnCap = 40
+
+// sampleDeal returns a random deal consistent with the brain's notes: known
+// cards where the notes put them, every hand its known size, one solution
+// card per category and every open mystery explained. Deals are drawn by
+// randomized search, so they fit the notes but are not equally likely. It
+// returns nil if no deal is found within a fixed effort.
+func (ai *AdvancedAIBrain) sampleDeal(rng Rng) *TestableGame {
+	tg := &TestableGame{Players: ai.players, Solution: make(map[string]string), Hands: make(map[string][]string), owner: make(map[string]string), config: ai.config}
+	room := make(map[string]int)
+	for _, p := range ai.players {
+		room[p] = ai.handSizes[p]
+	}
+	var free []string
+	for _, card := range ai.config.AllCards {
+		placed := false
+		for loc, status := range ai.knowledge[card] {
+			if status == StatusYes {
+				tg.owner[card] = loc
+				placed = true
+				if loc == "solution" {
+					tg.Solution[ai.config.CardToType[card]] = card
+				} else {
+					room[loc]--
+				}
+			}
+		}
+		if !placed {
+			free = append(free, card)
+		}
+	}
+	rng.Shuffle(len(free), func(i, j int) { free[i], free[j] = free[j], free[i] })
+
+	budget := 20000
+	var place func(i int) bool
+	place = func(i int) bool {
+		if budget--; budget < 0 {
+			return false
+		}
+		if i == len(free) {
+			for _, m := range ai.unresolvedSuggestions {
+				explained := false
+				for card := range m.PossibleCards {
+					explained = explained || tg.owner[card] == m.Disprover
+				}
+				if !explained {
+					return false
+				}
+			}
+			return true
+		}
+		card := free[i]
+		cat := ai.config.CardToType[card]
+		var options []string
+		for _, loc := range append(append([]string{}, ai.players...), "solution") {
+			if ai.knowledge[card][loc] != StatusMaybe {
+				continue
+			}
+			if loc == "solution" && tg.Solution[cat] == "" || loc != "solution" && room[loc] > 0 {
+				options = append(options, loc)
+			}
+		}
+		rng.Shuffle(len(options), func(a, b int) { options[a], options[b] = options[b], options[a] })
+		for _, loc := range options {
+			tg.owner[card] = loc
+			if loc == "solution" {
+				tg.Solution[cat] = card
+			} else {
+				room[loc]--
+			}
+			if place(i + 1) {
+				return true
+			}
+			if loc == "solution" {
+				delete(tg.Solution, cat)
+			} else {
+				room[loc]++
+			}
+		}
+		delete(tg.owner, card)
+		return false
+	}
+	if !place(0) {
+		return nil
+	}
+	for card, loc := range tg.owner {
+		if loc != "solution" {
+			tg.Hands[loc] = append(tg.Hands[loc], card)
+		}
+	}
+	return tg
+}
+
+// Rollout plays our own turns on a copy of the brain against the given deal,
+// opening with first (or the brain's own choice if nil), until every
+// solution card is known. Opponents always show a legal card; their turns
+// are not modelled. It returns the number of our turns taken, capped at
+// rolloutTurnCap.
+func (ai *AdvancedAIBrain) Rollout(deal *TestableGame, first map[string]string, rng Rng) int {
+	sim := ai.Clone()
+	sim.rng = rng
+	for turn := 1; turn <= rolloutTurnCap; turn++ {
+		suggestion := first
+		if turn > 1 || suggestion == nil {
+			suggestion = sim.MakeSuggestion()
+		}
+		disprover, shown := deal.Disprove(sim.name, suggestion, rng)
+		sim.ProcessTurnInfo(sim.name, disprover, shown, suggestion)
+		if len(sim._knownSolutionCards()) == len(sim.config.Categories) {
+			return turn
+		}
+	}
+	return rolloutTurnCap
+}
+
+// EndgamePlan is the result of PlanEndgame.
+type EndgamePlan struct {
+	Suggestion    map[string]string
+	ExpectedTurns float64 // Our own turns until the solution is forced.
+	Rollouts      int     // Deals each candidate was tried against.
+}
+
+// PlanEndgame tries each of the top candidates suggestions against the same
+// rollouts sampled deals and returns a plan for each, the one that forces
+// the solution in the fewest of our turns on average first. It returns nil
+// if no deal fits the notes.
+func (ai *AdvancedAIBrain) PlanEndgame(candidates, rollouts int, rng Rng) []EndgamePlan {
+	var deals []*TestableGame
+	for i := 0; i < rollouts*3 && len(deals) < rollouts; i++ {
+		if deal := ai.sampleDeal(rng); deal != nil {
+			deals = append(deals, deal)
+		}
+	}
+	if len(deals) == 0 {
+		return nil
+	}
+	var firsts []map[string]string
+	for _, opt := range ai.UsefulSuggestions() {
+		if len(firsts) == candidates {
+			break
+		}
+		firsts = append(firsts, maps.Clone(opt.Cards))
+	}
+	if len(firsts) == 0 {
+		firsts = append(firsts, ai.Clone().MakeSuggestion())
+	}
+	var plans []EndgamePlan
+	for _, first := range firsts {
+		total := 0
+		for _, deal := range deals {
+			total += ai.Rollout(deal, first, rng)
+		}
+		plans = append(plans, EndgamePlan{Suggestion: first, ExpectedTurns: float64(total) / float64(len(deals)), Rollouts: len(deals)})
+	}
+	slices.SortStableFunc(plans, func(a, b EndgamePlan) int { return cmp.Compare(a.ExpectedTurns, b.ExpectedTurns) })
+	return plans
+}
@@ -0,0 +1,137 @@
+// scenario.go
+// Shareable game scenarios: a full deal plus an optional turn log.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// ErrScenarioInvalid is returned when a scenario contradicts itself or the config.
+var ErrScenarioInvalid = errors.New("inconsistent scenario")
+
+// Scenario is a complete, shareable description of a game: the table, the
+// hidden solution, every player's hand and optionally what was played.
+type Scenario struct {
+	Players  []string            `json:"players"`
+	Solution map[string]string   `json:"solution"`
+	Hands    map[string][]string `json:"hands"`
+	Log      []TurnRecord        `json:"log,omitempty"`
+
+	// Set when the scenario was exported from a finished game.
+	Winner         string `json:"winner,omitempty"`
+	AccusationTurn int    `json:"accusation_turn,omitempty"`
+}
+
+// Write saves the scenario as indented JSON.
+func (sc *Scenario) Write(w io.Writer) error {
+	data, err := json.MarshalIndent(sc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// LoadScenario reads a scenario file and checks it against the config.
+func LoadScenario(path string, cfg GameConfig) (*Scenario, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sc Scenario
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := sc.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", path, ErrScenarioInvalid, err)
+	}
+	return &sc, nil
+}
+
+// Validate checks that the solution and hands exactly partition the deck and
+// that every logged disproval is consistent with the hands. It reports the
+// first inconsistency found.
+func (sc *Scenario) Validate(cfg GameConfig) error {
+	if !isCompleteSuggestion(cfg, sc.Solution) {
+		return fmt.Errorf("solution %v must name one card of each category", sc.Solution)
+	}
+
+	owner := make(map[string]string)
+	place := func(card, location string) error {
+		if _, ok := cfg.CardToType[card]; !ok {
+			return fmt.Errorf("%s holds unknown card %q", location, card)
+		}
+		if prev, dup := owner[card]; dup {
+			return fmt.Errorf("card %q is held by both %s and %s", card, prev, location)
+		}
+		owner[card] = location
+		return nil
+	}
+	for _, cat := range cfg.CategoryNames() {
+		if err := place(sc.Solution[cat], "the solution"); err != nil {
+			return err
+		}
+	}
+	isPlayer := make(map[string]bool)
+	for _, p := range sc.Players {
+		isPlayer[p] = true
+		for _, card := range sc.Hands[p] {
+			if err := place(card, p); err != nil {
+				return err
+			}
+		}
+	}
+	for p := range sc.Hands {
+		if !isPlayer[p] {
+			return fmt.Errorf("hand given for unknown player %q", p)
+		}
+	}
+	for _, card := range cfg.AllCards {
+		if _, ok := owner[card]; !ok {
+			return fmt.Errorf("card %q is missing from the solution and every hand", card)
+		}
+	}
+
+	for i, t := range sc.Log {
+		turn := i + 1
+		if !isPlayer[t.Suggester] {
+			return fmt.Errorf("turn %d: unknown suggester %q", turn, t.Suggester)
+		}
+		if !isCompleteSuggestion(cfg, t.Suggestion) {
+			return fmt.Errorf("turn %d: suggestion %v must name one card per category", turn, t.Suggestion)
+		}
+		if t.Disprover == "" {
+			if t.Shown != "" {
+				return fmt.Errorf("turn %d: card %q shown but nobody disproved", turn, t.Shown)
+			}
+			continue
+		}
+		if !isPlayer[t.Disprover] {
+			return fmt.Errorf("turn %d: unknown disprover %q", turn, t.Disprover)
+		}
+		if t.Shown == "" {
+			continue
+		}
+		if t.Suggestion[cfg.CardToType[t.Shown]] != t.Shown {
+			return fmt.Errorf("turn %d: shown card %q was not part of the suggestion", turn, t.Shown)
+		}
+		if owner[t.Shown] != t.Disprover {
+			return fmt.Errorf("turn %d: %s showed %q but it belongs to %s", turn, t.Disprover, t.Shown, owner[t.Shown])
+		}
+	}
+	return nil
+}
+
+func runVerifyScenario(path string) {
+	sc, err := LoadScenario(path, config)
+	if err != nil {
+		C.Warn.Printf("Could not verify scenario: %v\n", err)
+		return
+	}
+	C.Yes.Printf("Scenario OK: %d players, %d logged turns, solution %v.\n", len(sc.Players), len(sc.Log), values(sc.Solution))
+}
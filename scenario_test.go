@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testScenario deals the classic deck to A, B and C: the solution is each
+// category's first card and the rest go round the table in config order.
+func testScenario() *Scenario {
+	sc := &Scenario{Players: []string{"A", "B", "C"}, Solution: make(map[string]string), Hands: make(map[string][]string)}
+	i := 0
+	for _, cat := range config.CategoryNames() {
+		cards := config.CardsOf(cat)
+		sc.Solution[cat] = cards[0]
+		for _, card := range cards[1:] {
+			p := sc.Players[i%len(sc.Players)]
+			sc.Hands[p] = append(sc.Hands[p], card)
+			i++
+		}
+	}
+	return sc
+}
+
+// writeScenario saves sc to a temporary file and returns its path.
+func writeScenario(t *testing.T, sc *Scenario) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := sc.Write(f); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadScenarioRejectsInconsistencies(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		spoil func(sc *Scenario)
+		want  string // In the error; empty if the scenario is fine.
+	}{
+		{"consistent", func(sc *Scenario) {}, ""},
+		{"missing card", func(sc *Scenario) {
+			sc.Hands["C"] = sc.Hands["C"][1:]
+		}, "is missing from the solution and every hand"},
+		{"duplicate card", func(sc *Scenario) {
+			sc.Hands["B"] = append(sc.Hands["B"], sc.Hands["A"][0])
+		}, "is held by both A and B"},
+		{"shown card not held", func(sc *Scenario) {
+			// A shows C a card from B's hand.
+			shown := sc.Hands["B"][0]
+			suggestion := maps.Clone(sc.Solution)
+			suggestion[config.CardToType[shown]] = shown
+			sc.Log = []TurnRecord{{Suggester: "C", Suggestion: suggestion, Disprover: "A", Shown: shown}}
+		}, "turn 1: A showed"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sc := testScenario()
+			tc.spoil(sc)
+			_, err := LoadScenario(writeScenario(t, sc), config)
+			if tc.want == "" {
+				if err != nil {
+					t.Fatalf("LoadScenario: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrScenarioInvalid) || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("LoadScenario = %v, want %v mentioning %q", err, ErrScenarioInvalid, tc.want)
+			}
+		})
+	}
+}
+
+// tinyScenario is a two-player game small enough to reason about by hand.
+func tinyScenario(t *testing.T) (*Scenario, GameConfig) {
+	t.Helper()
+	cfg := GameConfig{Suspects: []string{"S1", "S2", "S3"}, Weapons: []string{"W1", "W2"}, Rooms: []string{"R1", "R2"}}
+	cfg.buildIndex()
+	sc := &Scenario{
+		Players:  []string{"A", "B"},
+		Solution: map[string]string{"suspects": "S1", "weapons": "W1", "rooms": "R1"},
+		Hands:    map[string][]string{"A": {"S2", "W2"}, "B": {"S3", "R2"}},
+		Log: []TurnRecord{
+			{Suggester: "A", Suggestion: map[string]string{"suspects": "S3", "weapons": "W2", "rooms": "R2"}, Disprover: "B", Shown: "S3"},
+			{Suggester: "A", Suggestion: map[string]string{"suspects": "S2", "weapons": "W2", "rooms": "R2"}, Disprover: "B", Shown: "R2"},
+		},
+	}
+	if err := sc.Validate(cfg); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	return sc, cfg
+}
+
+func TestEarliestSolveTurnOnATinyScenario(t *testing.T) {
+	sc, cfg := tinyScenario(t)
+	// A holds W2, so W1 is the weapon; seeing S3 and then R2 leaves only S1
+	// and R1. B sees nothing new and can never tell which of S1 and S2, or
+	// W1 and W2, A holds.
+	for player, want := range map[string]int{"A": 2, "B": -1} {
+		if got := EarliestSolveTurn(sc, cfg, player); got != want {
+			t.Errorf("EarliestSolveTurn(%s) = %d, want %d", player, got, want)
+		}
+	}
+}
+
+func TestChallengeRoundTripsWithItsPar(t *testing.T) {
+	sc, cfg := tinyScenario(t)
+	ch, err := ExportChallenge(sc, cfg, "A", 1)
+	if err != nil {
+		t.Fatalf("ExportChallenge: %v", err)
+	}
+	// The solver pins A's solution down on the second turn, so from turn 1
+	// par is one more turn.
+	if ch.Par != 1 {
+		t.Errorf("Par = %d, want 1", ch.Par)
+	}
+
+	path := filepath.Join(t.TempDir(), "challenge.json")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ch.Write(f); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	f.Close()
+	loaded, err := LoadChallenge(path, cfg)
+	if err != nil {
+		t.Fatalf("LoadChallenge: %v", err)
+	}
+	if !reflect.DeepEqual(*loaded, ch) {
+		t.Errorf("round trip changed the challenge:\n got %+v\nwant %+v", *loaded, ch)
+	}
+
+	if _, err := ExportChallenge(sc, cfg, "B", 0); err == nil {
+		t.Error("exporting a challenge B can never solve succeeded")
+	}
+}
@@ -0,0 +1,380 @@
+// server.go
+// An HTTP/JSON service exposing the deduction engine to other tools.
+
+package main
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// apiServer holds the detective sessions of every API client. Sessions idle
+// for longer than ttl are evicted, and at most maxSessions may exist at once.
+type apiServer struct {
+	cfg         GameConfig
+	ttl         time.Duration
+	maxSessions int
+	mu          sync.Mutex
+	sessions    map[string]*apiSession
+}
+
+// apiSession is one client's brain. Its lock serializes requests to it.
+type apiSession struct {
+	mu         sync.Mutex
+	brain      *AdvancedAIBrain
+	lastAccess time.Time // Guarded by apiServer.mu.
+}
+
+func newAPIServer(cfg GameConfig, ttl time.Duration, maxSessions int) *apiServer {
+	return &apiServer{cfg: cfg, ttl: ttl, maxSessions: maxSessions, sessions: make(map[string]*apiSession)}
+}
+
+// evictExpired drops every session idle since before now-ttl and returns how
+// many were removed.
+func (s *apiServer) evictExpired(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	evicted := 0
+	for id, sess := range s.sessions {
+		if now.Sub(sess.lastAccess) > s.ttl {
+			delete(s.sessions, id)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// runJanitor evicts expired sessions every interval until stop is closed.
+func (s *apiServer) runJanitor(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			if n := s.evictExpired(now); n > 0 {
+				log.Infof("Evicted %d idle API session(s).", n)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// detectivePage is a browser front end for the API.
+//
+//go:embed web/detective.html
+var detectivePage []byte
+
+func (s *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handlePage)
+	mux.HandleFunc("GET /config", s.handleConfig)
+	mux.HandleFunc("POST /sessions", s.handleCreateSession)
+	mux.HandleFunc("DELETE /sessions/{id}", s.handleDeleteSession)
+	mux.HandleFunc("POST /sessions/{id}/turns", s.withSession(s.handleLogTurn))
+	mux.HandleFunc("POST /sessions/{id}/reveals", s.withSession(s.handleReveal))
+	mux.HandleFunc("GET /sessions/{id}/notes", s.withSession(s.handleNotes))
+	mux.HandleFunc("GET /sessions/{id}/suggestion", s.withSession(s.handleSuggestion))
+	mux.HandleFunc("GET /sessions/{id}/solution", s.withSession(s.handleSolution))
+	return mux
+}
+
+func (s *apiServer) handlePage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(detectivePage)
+}
+
+type configResponse struct {
+	Categories []Category `json:"categories"`
+}
+
+// handleConfig lists the cards of every category, so clients can offer them.
+func (s *apiServer) handleConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, configResponse{Categories: s.cfg.Categories})
+}
+
+type createSessionRequest struct {
+	Players   []string       `json:"players"`
+	Me        string         `json:"me"`
+	Hand      []string       `json:"hand"`
+	HandSizes map[string]int `json:"hand_sizes,omitempty"` // Defaults to a seating-order deal.
+}
+
+func (s *apiServer) handleCreateSession(w http.ResponseWriter, r *http.Request) {
+	var req createSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: %v", err)
+		return
+	}
+	if err := s.cfg.CheckPlayers(len(req.Players)); err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	seen := make(map[string]bool)
+	for _, p := range req.Players {
+		if p == "" || p == "solution" || seen[p] {
+			writeError(w, http.StatusBadRequest, "invalid or duplicate player name %q", p)
+			return
+		}
+		seen[p] = true
+	}
+	if !seen[req.Me] {
+		writeError(w, http.StatusBadRequest, "me (%q) must be one of the players", req.Me)
+		return
+	}
+	for _, card := range req.Hand {
+		if _, ok := s.cfg.CardToType[card]; !ok {
+			writeError(w, http.StatusBadRequest, "unknown card %q", card)
+			return
+		}
+	}
+	if err := s.checkHandSizes(req); err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	brain := s.cfg.AI.NewBrain()
+	brain.Setup(s.cfg, req.Players, req.Me)
+	brain.ReceiveHand(req.Hand)
+	if len(req.HandSizes) > 0 {
+		brain.SetHandSizes(req.HandSizes)
+	}
+
+	id := newSessionID()
+	s.mu.Lock()
+	if len(s.sessions) >= s.maxSessions {
+		s.mu.Unlock()
+		writeError(w, http.StatusTooManyRequests, "session limit of %d reached; try again later", s.maxSessions)
+		return
+	}
+	s.sessions[id] = &apiSession{brain: brain, lastAccess: time.Now()}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+// checkHandSizes rejects hand sizes that no deal could produce: each must fit
+// in the dealt cards, the caller's own must match their hand, and together
+// with the seating-order sizes of unlisted players they must account for
+// every dealt card. The deduction cost also grows quickly with hand size.
+func (s *apiServer) checkHandSizes(req createSessionRequest) error {
+	if len(req.HandSizes) == 0 {
+		return nil
+	}
+	dealt := len(s.cfg.AllCards) - len(s.cfg.Categories)
+	sizes := dealtHandSizes(dealt, req.Players)
+	sizes[req.Me] = len(req.Hand)
+	for p, n := range req.HandSizes {
+		switch {
+		case !slices.Contains(req.Players, p) || n < 0 || n > dealt:
+			return fmt.Errorf("bad hand size for %q", p)
+		case p == req.Me && n != len(req.Hand):
+			return fmt.Errorf("hand size for %q must match the %d cards in hand", p, len(req.Hand))
+		}
+		sizes[p] = n
+	}
+	total := 0
+	for _, n := range sizes {
+		total += n
+	}
+	if total != dealt {
+		return fmt.Errorf("hand sizes add up to %d, but %d cards are dealt", total, dealt)
+	}
+	return nil
+}
+
+func (s *apiServer) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	_, ok := s.sessions[id]
+	delete(s.sessions, id)
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "no session %q", id)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// withSession looks up the session named in the path and holds its lock for
+// the duration of the handler.
+func (s *apiServer) withSession(h func(http.ResponseWriter, *http.Request, *AdvancedAIBrain)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		s.mu.Lock()
+		sess, ok := s.sessions[id]
+		if ok {
+			sess.lastAccess = time.Now()
+		}
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, "no session %q", id)
+			return
+		}
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		h(w, r, sess.brain)
+	}
+}
+
+func (s *apiServer) handleLogTurn(w http.ResponseWriter, r *http.Request, brain *AdvancedAIBrain) {
+	var t TurnRecord
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: %v", err)
+		return
+	}
+	if !brain.isPlayer(t.Suggester) || (t.Disprover != "" && !brain.isPlayer(t.Disprover)) {
+		writeError(w, http.StatusBadRequest, "suggester and disprover must be players in this session")
+		return
+	}
+	if !isCompleteSuggestion(s.cfg, t.Suggestion) {
+		writeError(w, http.StatusBadRequest, "suggestion must name one card of each category")
+		return
+	}
+	if t.Shown != "" && (t.Disprover == "" || t.Suggestion[s.cfg.CardToType[t.Shown]] != t.Shown) {
+		writeError(w, http.StatusBadRequest, "shown card must be one of the suggested cards and needs a disprover")
+		return
+	}
+	if err := brain.ValidateTurn(t.Suggester, t.Disprover, t.Shown, t.Suggestion); err != nil {
+		writeError(w, http.StatusConflict, "turn contradicts the notes: %v", err)
+		return
+	}
+	brain.ProcessTurnInfo(t.Suggester, t.Disprover, t.Shown, t.Suggestion)
+	writeJSON(w, http.StatusOK, brain.notesJSON())
+}
+
+type revealRequest struct {
+	Player string `json:"player"`
+	Card   string `json:"card"`
+}
+
+func (s *apiServer) handleReveal(w http.ResponseWriter, r *http.Request, brain *AdvancedAIBrain) {
+	var req revealRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: %v", err)
+		return
+	}
+	if _, ok := s.cfg.CardToType[req.Card]; !ok || !brain.isPlayer(req.Player) {
+		writeError(w, http.StatusBadRequest, "unknown player or card")
+		return
+	}
+	brain.ProcessTurnInfo("Game Event", req.Player, req.Card, nil)
+	writeJSON(w, http.StatusOK, brain.notesJSON())
+}
+
+// handleNotes returns the grid as JSON, or as a plain-text table with ?format=text.
+func (s *apiServer) handleNotes(w http.ResponseWriter, r *http.Request, brain *AdvancedAIBrain) {
+	if r.URL.Query().Get("format") == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, brain.Notes())
+		return
+	}
+	writeJSON(w, http.StatusOK, brain.notesJSON())
+}
+
+func (s *apiServer) handleSuggestion(w http.ResponseWriter, r *http.Request, brain *AdvancedAIBrain) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"suggestion": brain.MakeSuggestion()})
+}
+
+type solutionResponse struct {
+	Known      map[string]string `json:"known"`
+	Complete   bool              `json:"complete"`
+	BestGuess  map[string]string `json:"best_guess"`
+	Confidence float64           `json:"confidence"`
+}
+
+func (s *apiServer) handleSolution(w http.ResponseWriter, r *http.Request, brain *AdvancedAIBrain) {
+	known := brain._knownSolutionCards()
+	guess, confidence := brain.BestGuessSolution()
+	writeJSON(w, http.StatusOK, solutionResponse{Known: known, Complete: len(known) == len(s.cfg.Categories), BestGuess: guess, Confidence: confidence})
+}
+
+// notesRow is one card's row of the notes grid.
+type notesRow struct {
+	Card   string                `json:"card"`
+	Type   string                `json:"type"`
+	Status map[string]CardStatus `json:"status"`
+}
+
+type notesResponse struct {
+	Players []string   `json:"players"`
+	Cards   []notesRow `json:"cards"`
+}
+
+func (ai *AdvancedAIBrain) notesJSON() notesResponse {
+	resp := notesResponse{Players: ai.players}
+	for _, card := range ai.config.AllCards {
+		row := notesRow{Card: card, Type: ai.config.CardToType[card], Status: make(map[string]CardStatus)}
+		for loc, status := range ai.knowledge[card] {
+			row.Status[loc] = status
+		}
+		resp.Cards = append(resp.Cards, row)
+	}
+	return resp
+}
+
+func (ai *AdvancedAIBrain) isPlayer(name string) bool {
+	for _, p := range ai.players {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // crypto/rand never fails on supported platforms.
+	}
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeJSON(w, status, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
+
+// browseAddr turns a listen address such as ":8080" into one a browser can open.
+func browseAddr(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "localhost" + addr
+	}
+	return addr
+}
+
+func runServeAPI(addr string, ttl time.Duration, maxSessions int) {
+	// Per-request deduction narration is noise in a server log.
+	if log.GetLevel() > logrus.WarnLevel {
+		log.SetLevel(logrus.WarnLevel)
+	}
+	if ttl <= 0 || maxSessions < 1 {
+		C.Warn.Println("The session TTL and session limit must both be positive.")
+		return
+	}
+	srv := newAPIServer(config, ttl, maxSessions)
+	stop := make(chan struct{})
+	defer close(stop)
+	go srv.runJanitor(min(ttl, time.Minute), stop)
+
+	C.Info.Printf("Serving the deduction API on %s (sessions expire after %s idle, at most %d)\n", addr, ttl, maxSessions)
+	C.Info.Printf("Open http://%s/ in a browser for the detective page.\n", browseAddr(addr))
+	if err := http.ListenAndServe(addr, srv.routes()); err != nil {
+		log.Fatalf("API server failed: %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// serve sends one request to the server's routes and returns the response.
+func serve(s *apiServer, method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+// meHand is six classic cards for "me" at a three-player table.
+const meHand = `["Miss Scarlett", "Colonel Mustard", "Candlestick", "Dagger", "Kitchen", "Ballroom"]`
+
+// createSession opens a session for me, you and them and returns its id.
+func createSession(t *testing.T, s *apiServer) string {
+	t.Helper()
+	rec := serve(s, "POST", "/sessions", `{"players": ["me", "you", "them"], "me": "me", "hand": `+meHand+`}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create session: %d %s", rec.Code, rec.Body)
+	}
+	var resp map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp["id"]
+}
+
+func TestServerConfigAndPage(t *testing.T) {
+	s := newAPIServer(config, time.Hour, 10)
+	rec := serve(s, "GET", "/config", "")
+	var resp configResponse
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &resp) != nil || len(resp.Categories) != len(config.Categories) {
+		t.Errorf("GET /config = %d %s", rec.Code, rec.Body)
+	}
+	if rec := serve(s, "GET", "/", ""); rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("GET / = %d, %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestCreateSessionRejectsBadRequests(t *testing.T) {
+	s := newAPIServer(config, time.Hour, 10)
+	for _, tc := range []struct {
+		name, body, want string
+	}{
+		{"bad JSON", `{"players": [`, "invalid JSON"},
+		{"too few players", `{"players": ["me"], "me": "me"}`, "player"},
+		{"duplicate player", `{"players": ["me", "you", "you"], "me": "me"}`, "duplicate player"},
+		{"player named solution", `{"players": ["me", "solution"], "me": "me"}`, "invalid or duplicate player"},
+		{"bad me", `{"players": ["me", "you", "them"], "me": "nobody"}`, "must be one of the players"},
+		{"bad card", `{"players": ["me", "you", "them"], "me": "me", "hand": ["Spoon"]}`, `unknown card "Spoon"`},
+		{"hand size of a stranger", `{"players": ["me", "you", "them"], "me": "me", "hand": ` + meHand + `, "hand_sizes": {"stranger": 6}}`, `bad hand size for "stranger"`},
+		{"negative hand size", `{"players": ["me", "you", "them"], "me": "me", "hand": ` + meHand + `, "hand_sizes": {"you": -1}}`, `bad hand size for "you"`},
+		{"own size not the hand", `{"players": ["me", "you", "them"], "me": "me", "hand": ` + meHand + `, "hand_sizes": {"me": 5}}`, "must match the 6 cards in hand"},
+		{"sizes not the deal", `{"players": ["me", "you", "them"], "me": "me", "hand": ` + meHand + `, "hand_sizes": {"you": 7}}`, "add up to 19, but 18 cards are dealt"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(s, "POST", "/sessions", tc.body)
+			var resp struct{ Error string }
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if rec.Code != http.StatusBadRequest || !strings.Contains(resp.Error, tc.want) {
+				t.Errorf("POST /sessions = %d %s, want 400 mentioning %q", rec.Code, rec.Body, tc.want)
+			}
+		})
+	}
+	rec := serve(s, "POST", "/sessions", `{"players": ["me", "you", "them"], "me": "me", "hand": `+meHand+`, "hand_sizes": {"you": 7, "them": 5}}`)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("uneven but possible hand sizes: %d %s", rec.Code, rec.Body)
+	}
+	if len(s.sessions) != 1 {
+		t.Errorf("%d sessions exist, want only the valid one", len(s.sessions))
+	}
+}
+
+func TestSessionEndpoints(t *testing.T) {
+	s := newAPIServer(config, time.Hour, 10)
+	id := createSession(t, s)
+	path := "/sessions/" + id
+
+	// you shows me the Rope.
+	turn := `{"suggester": "me", "suggestion": {"suspects": "Mrs. White", "weapons": "Rope", "rooms": "Hall"}, "disprover": "you", "shown": "Rope"}`
+	rec := serve(s, "POST", path+"/turns", turn)
+	var notes notesResponse
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &notes) != nil {
+		t.Fatalf("POST turns = %d %s", rec.Code, rec.Body)
+	}
+	for _, row := range notes.Cards {
+		if row.Card == "Rope" && row.Status["you"] != StatusYes {
+			t.Errorf("Rope with you is %s, want Yes", row.Status["you"])
+		}
+	}
+	for _, tc := range []struct {
+		name, endpoint, body string
+		want                 int
+	}{
+		{"unknown suggester", "/turns", `{"suggester": "stranger", "suggestion": {"suspects": "Mrs. White", "weapons": "Rope", "rooms": "Hall"}}`, http.StatusBadRequest},
+		{"incomplete suggestion", "/turns", `{"suggester": "you", "suggestion": {"suspects": "Mrs. White"}}`, http.StatusBadRequest},
+		{"shown card not suggested", "/turns", `{"suggester": "me", "suggestion": {"suspects": "Mrs. White", "weapons": "Rope", "rooms": "Hall"}, "disprover": "you", "shown": "Lounge"}`, http.StatusBadRequest},
+		{"contradicting turn", "/turns", `{"suggester": "me", "suggestion": {"suspects": "Mrs. White", "weapons": "Rope", "rooms": "Hall"}, "disprover": "them", "shown": "Rope"}`, http.StatusConflict},
+		{"bad reveal card", "/reveals", `{"player": "you", "card": "Spoon"}`, http.StatusBadRequest},
+		{"bad reveal player", "/reveals", `{"player": "stranger", "card": "Lounge"}`, http.StatusBadRequest},
+		{"reveal", "/reveals", `{"player": "them", "card": "Lounge"}`, http.StatusOK},
+	} {
+		if rec := serve(s, "POST", path+tc.endpoint, tc.body); rec.Code != tc.want {
+			t.Errorf("%s: POST %s = %d %s, want %d", tc.name, tc.endpoint, rec.Code, rec.Body, tc.want)
+		}
+	}
+
+	if rec := serve(s, "GET", path+"/notes?format=text", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Rope") {
+		t.Errorf("GET notes as text = %d %s", rec.Code, rec.Body)
+	}
+	var suggestion struct{ Suggestion map[string]string }
+	if rec := serve(s, "GET", path+"/suggestion", ""); rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &suggestion) != nil || !isCompleteSuggestion(config, suggestion.Suggestion) {
+		t.Errorf("GET suggestion = %d %s", rec.Code, rec.Body)
+	}
+	var solution solutionResponse
+	if rec := serve(s, "GET", path+"/solution", ""); rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &solution) != nil || solution.Complete {
+		t.Errorf("GET solution = %d %s", rec.Code, rec.Body)
+	}
+
+	if rec := serve(s, "DELETE", path, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE = %d %s", rec.Code, rec.Body)
+	}
+	if rec := serve(s, "GET", path+"/notes", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET notes after delete = %d, want 404", rec.Code)
+	}
+	if rec := serve(s, "DELETE", path, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("DELETE after delete = %d, want 404", rec.Code)
+	}
+}
+
+func TestIdleSessionsExpire(t *testing.T) {
+	s := newAPIServer(config, time.Minute, 10)
+	idle, busy := createSession(t, s), createSession(t, s)
+	s.sessions[idle].lastAccess = time.Now().Add(-2 * time.Minute)
+
+	if n := s.evictExpired(time.Now()); n != 1 {
+		t.Fatalf("evicted %d sessions, want 1", n)
+	}
+	if rec := serve(s, "GET", "/sessions/"+idle+"/notes", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expired session: GET notes = %d, want 404", rec.Code)
+	}
+	if rec := serve(s, "GET", "/sessions/"+busy+"/notes", ""); rec.Code != http.StatusOK {
+		t.Errorf("fresh session: GET notes = %d, want 200", rec.Code)
+	}
+	// Using a session keeps it alive.
+	s.sessions[busy].lastAccess = time.Now().Add(-50 * time.Second)
+	serve(s, "GET", "/sessions/"+busy+"/suggestion", "")
+	if n := s.evictExpired(time.Now().Add(30 * time.Second)); n != 0 {
+		t.Errorf("evicted %d sessions in use, want 0", n)
+	}
+}
+
+func TestSessionLimit(t *testing.T) {
+	s := newAPIServer(config, time.Hour, 2)
+	first := createSession(t, s)
+	createSession(t, s)
+	body := `{"players": ["me", "you", "them"], "me": "me", "hand": ` + meHand + `}`
+	if rec := serve(s, "POST", "/sessions", body); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("third session: %d %s, want 429", rec.Code, rec.Body)
+	}
+	serve(s, "DELETE", "/sessions/"+first, "")
+	if rec := serve(s, "POST", "/sessions", body); rec.Code != http.StatusCreated {
+		t.Errorf("after a delete: %d %s, want 201", rec.Code, rec.Body)
+	}
+}
+
+// TestConcurrentSessionUse is meant for go test -race: clients share a
+// session and open and close their own while the janitor runs.
+func TestConcurrentSessionUse(t *testing.T) {
+	s := newAPIServer(config, time.Millisecond, 100)
+	stop := make(chan struct{})
+	go s.runJanitor(time.Millisecond, stop)
+	defer close(stop)
+
+	shared := createSession(t, s)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				serve(s, "GET", "/sessions/"+shared+"/suggestion", "")
+				serve(s, "POST", "/sessions/"+shared+"/reveals", `{"player": "you", "card": "Rope"}`)
+				rec := serve(s, "POST", "/sessions", `{"players": ["me", "you", "them"], "me": "me", "hand": `+meHand+`}`)
+				var resp map[string]string
+				if json.Unmarshal(rec.Body.Bytes(), &resp) == nil && resp["id"] != "" {
+					serve(s, "GET", "/sessions/"+resp["id"]+"/notes", "")
+					serve(s, "DELETE", "/sessions/"+resp["id"], "")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
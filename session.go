@@ -0,0 +1,233 @@
+// session.go
+// Saving and restoring detective-mode notes between sittings.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// savedSession is the on-disk form of a detective brain. Mysteries are
+// stored as sorted card lists because JSON has no sets.
+type savedSession struct {
+	Name                  string                           `json:"name"`
+	Players               []string                         `json:"players"`
+	Hand                  []string                         `json:"hand"`
+	Knowledge             map[string]map[string]CardStatus `json:"knowledge"`
+	UnresolvedSuggestions []savedMystery                   `json:"unresolved_suggestions"`
+	HandSizes             map[string]int                   `json:"hand_sizes,omitempty"`
+	History               []TurnRecord                     `json:"history,omitempty"` // Kept so undo works after a reload.
+	Progress              []int                            `json:"progress,omitempty"`
+	Disclosed             map[string][]string              `json:"disclosed,omitempty"`
+}
+
+type savedMystery struct {
+	Disprover     string   `json:"disprover"`
+	PossibleCards []string `json:"possible_cards"`
+}
+
+// SaveSession writes the brain's notes to a JSON file so a long game can be
+// resumed later with LoadSession.
+func (ai *AdvancedAIBrain) SaveSession(path string) error {
+	saved := savedSession{
+		Name:      ai.name,
+		Players:   ai.players,
+		Hand:      ai.Hand(),
+		Knowledge: ai.knowledge,
+		HandSizes: ai.handSizes,
+		History:   ai.history,
+		Progress:  ai.progress,
+		Disclosed: make(map[string][]string),
+	}
+	for _, m := range ai.unresolvedSuggestions {
+		saved.UnresolvedSuggestions = append(saved.UnresolvedSuggestions, savedMystery{Disprover: m.Disprover, PossibleCards: sortedKeys(m.PossibleCards)})
+	}
+	for p, cards := range ai.disclosed {
+		for card := range cards {
+			saved.Disclosed[p] = append(saved.Disclosed[p], card)
+		}
+		sort.Strings(saved.Disclosed[p])
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LoadSession restores a brain saved by SaveSession. The file must describe
+// exactly the cards of the current config.
+func LoadSession(path string) (*AdvancedAIBrain, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var saved savedSession
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := saved.validate(config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	ai := config.AI.NewBrain()
+	ai.Setup(config, saved.Players, saved.Name)
+	for _, card := range saved.Hand {
+		ai.hand[card] = struct{}{}
+	}
+	for card, row := range saved.Knowledge {
+		for loc, status := range row {
+			ai.knowledge[card][loc] = status
+		}
+	}
+	for _, m := range saved.UnresolvedSuggestions {
+		possible := make(map[string]struct{})
+		for _, card := range m.PossibleCards {
+			possible[card] = struct{}{}
+		}
+		ai.unresolvedSuggestions = append(ai.unresolvedSuggestions, UnresolvedSuggestion{Disprover: m.Disprover, PossibleCards: possible})
+	}
+	for p, n := range saved.HandSizes {
+		ai.handSizes[p] = n
+	}
+	ai.history = saved.History
+	ai.progress = saved.Progress
+	for p, cards := range saved.Disclosed {
+		ai.disclosed[p] = make(map[string]bool)
+		for _, card := range cards {
+			ai.disclosed[p][card] = true
+		}
+	}
+	// A solution found before saving was already announced.
+	ai.solutionAnnounced = len(ai._knownSolutionCards()) == len(config.Categories)
+	return ai, nil
+}
+
+// validate checks the saved notes against the config: the same cards, a
+// known status for every player and the solution, and no unknown names.
+func (saved *savedSession) validate(cfg GameConfig) error {
+	isPlayer := make(map[string]bool)
+	for _, p := range saved.Players {
+		if p == "" || p == "solution" || isPlayer[p] {
+			return fmt.Errorf("invalid or duplicate player name %q", p)
+		}
+		isPlayer[p] = true
+	}
+	if !isPlayer[saved.Name] {
+		return fmt.Errorf("%q is not one of the players", saved.Name)
+	}
+	if len(saved.Knowledge) != len(cfg.AllCards) {
+		return fmt.Errorf("notes cover %d cards but the config has %d", len(saved.Knowledge), len(cfg.AllCards))
+	}
+	for _, card := range cfg.AllCards {
+		row, ok := saved.Knowledge[card]
+		if !ok {
+			return fmt.Errorf("notes are missing card %q", card)
+		}
+		if len(row) != len(saved.Players)+1 {
+			return fmt.Errorf("notes for %q have %d columns, want %d", card, len(row), len(saved.Players)+1)
+		}
+		for loc, status := range row {
+			if loc != "solution" && !isPlayer[loc] {
+				return fmt.Errorf("notes for %q mention unknown location %q", card, loc)
+			}
+			if status != StatusYes && status != StatusNo && status != StatusMaybe {
+				return fmt.Errorf("notes for %q have invalid status %q", card, status)
+			}
+		}
+	}
+	for _, card := range saved.Hand {
+		if _, ok := cfg.CardToType[card]; !ok {
+			return fmt.Errorf("hand holds unknown card %q", card)
+		}
+	}
+	if len(saved.Progress) != len(saved.History) {
+		return fmt.Errorf("progress has %d entries but history has %d", len(saved.Progress), len(saved.History))
+	}
+	for _, m := range saved.UnresolvedSuggestions {
+		if !isPlayer[m.Disprover] {
+			return fmt.Errorf("mystery names unknown player %q", m.Disprover)
+		}
+		for _, card := range m.PossibleCards {
+			if _, ok := cfg.CardToType[card]; !ok {
+				return fmt.Errorf("mystery holds unknown card %q", card)
+			}
+		}
+	}
+	return nil
+}
+
+// ExportKnowledge returns just the notes grid as indented JSON, small enough
+// to paste into a chat: the players and, for each card, its status with
+// every player and the solution. Hand, mysteries and history are left out.
+func (ai *AdvancedAIBrain) ExportKnowledge() ([]byte, error) {
+	return json.MarshalIndent(ai.notesJSON(), "", "  ")
+}
+
+// ImportKnowledge replaces the notes grid with one written by
+// ExportKnowledge. The grid must be for the same players and the config's
+// cards, agree with our own hand and not contradict itself. Open mysteries
+// and the reasoning behind earlier placements describe the old grid, so
+// both are dropped. Logged entries are kept but not re-checked, so undo and
+// edit rebuild without the import.
+func (ai *AdvancedAIBrain) ImportKnowledge(data []byte) error {
+	var grid notesResponse
+	if err := json.Unmarshal(data, &grid); err != nil {
+		return fmt.Errorf("parsing notes: %w", err)
+	}
+	if len(grid.Players) != len(ai.players) {
+		return fmt.Errorf("notes are for %d players, not %d", len(grid.Players), len(ai.players))
+	}
+	for _, p := range grid.Players {
+		if !slices.Contains(ai.players, p) {
+			return fmt.Errorf("notes mention unknown player %q", p)
+		}
+	}
+	knowledge := make(map[string]map[string]CardStatus)
+	for _, row := range grid.Cards {
+		if _, ok := ai.config.CardToType[row.Card]; !ok {
+			return fmt.Errorf("notes mention unknown card %q", row.Card)
+		}
+		if knowledge[row.Card] != nil {
+			return fmt.Errorf("notes list %q twice", row.Card)
+		}
+		knowledge[row.Card] = make(map[string]CardStatus)
+		for _, loc := range append(append([]string{}, ai.players...), "solution") {
+			status := row.Status[loc]
+			if status != StatusYes && status != StatusNo && status != StatusMaybe {
+				return fmt.Errorf("notes for %q have invalid status %q for %s", row.Card, status, loc)
+			}
+			knowledge[row.Card][loc] = status
+		}
+		if _, mine := ai.hand[row.Card]; mine != (knowledge[row.Card][ai.name] == StatusYes) {
+			return fmt.Errorf("notes for %q disagree with your hand", row.Card)
+		}
+		if len(row.Status) != len(ai.players)+1 {
+			return fmt.Errorf("notes for %q have %d columns, want %d", row.Card, len(row.Status), len(ai.players)+1)
+		}
+	}
+	if len(knowledge) != len(ai.config.AllCards) {
+		return fmt.Errorf("notes cover %d cards but the config has %d", len(knowledge), len(ai.config.AllCards))
+	}
+	old := ai.knowledge
+	ai.knowledge = knowledge
+	if bad := ai.Contradictions(); len(bad) > 0 {
+		ai.knowledge = old
+		var problems []string
+		for _, card := range slices.Sorted(maps.Keys(bad)) {
+			problems = append(problems, fmt.Sprintf("%s is %s", card, bad[card]))
+		}
+		return fmt.Errorf("notes contradict themselves: %s", strings.Join(problems, "; "))
+	}
+	ai.reasoning = nil
+	ai.unresolvedSuggestions = []UnresolvedSuggestion{}
+	ai.solvedBy = ""
+	ai._runDeductionLoop()
+	return nil
+}
@@ -0,0 +1,89 @@
+// showstrategy.go
+// How an AI disprover picks which of its cards to show.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ShowStrategy picks the card a brain shows to disprove a suggestion.
+// canShow is sorted and never empty.
+type ShowStrategy interface {
+	ChooseCard(ai *AdvancedAIBrain, suggester string, canShow []string) string
+}
+
+// RandomShowStrategy shows any matching card, leaving the choice to the Rng.
+type RandomShowStrategy struct{}
+
+func (RandomShowStrategy) ChooseCard(ai *AdvancedAIBrain, suggester string, canShow []string) string {
+	return canShow[ai.rng.Intn(len(canShow))]
+}
+
+// MinimalInfoShowStrategy gives away as little as possible: if the suggester
+// has already seen one of the matching cards from us, it shows that one
+// again. Otherwise it falls back to a random choice.
+type MinimalInfoShowStrategy struct{}
+
+func (MinimalInfoShowStrategy) ChooseCard(ai *AdvancedAIBrain, suggester string, canShow []string) string {
+	var seen []string
+	for _, card := range canShow {
+		if ai.disclosed[suggester][card] {
+			seen = append(seen, card)
+		}
+	}
+	if len(seen) > 0 {
+		log.Debugf("[%s's Brain] %s has already seen %v; showing one of those again.", ai.name, suggester, seen)
+		return seen[ai.rng.Intn(len(seen))]
+	}
+	return RandomShowStrategy{}.ChooseCard(ai, suggester, canShow)
+}
+
+// SafeShowStrategy prefers cards from categories whose solution we already
+// know: the suggester may be closing in on those anyway, while a card from an
+// open category narrows down a part of the answer we are still racing for.
+// Without such a card it falls back to a random choice.
+type SafeShowStrategy struct{}
+
+func (SafeShowStrategy) ChooseCard(ai *AdvancedAIBrain, suggester string, canShow []string) string {
+	known := ai._knownSolutionCards()
+	var safe []string
+	for _, card := range canShow {
+		if _, ok := known[ai.config.CardToType[card]]; ok {
+			safe = append(safe, card)
+		}
+	}
+	if len(safe) > 0 && len(safe) < len(canShow) {
+		log.Debugf("[%s's Brain] Showing %s one of %v from a solved category.", ai.name, suggester, safe)
+		return safe[ai.rng.Intn(len(safe))]
+	}
+	return RandomShowStrategy{}.ChooseCard(ai, suggester, canShow)
+}
+
+// DefaultShowStrategy is used by brains that were not given one.
+var DefaultShowStrategy ShowStrategy = RandomShowStrategy{}
+
+// showStrategies maps the -show flag values to strategies.
+var showStrategies = map[string]ShowStrategy{
+	"random":  RandomShowStrategy{},
+	"minimal": MinimalInfoShowStrategy{},
+	"safe":    SafeShowStrategy{},
+}
+
+// ShowStrategyByName looks up a strategy by its -show flag value.
+func ShowStrategyByName(name string) (ShowStrategy, error) {
+	if s, ok := showStrategies[name]; ok {
+		return s, nil
+	}
+	names := make([]string, 0, len(showStrategies))
+	for n := range showStrategies {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown show strategy %q (want one of %v)", name, names)
+}
+
+// SetShowStrategy chooses how the brain picks a card to show; nil means
+// DefaultShowStrategy.
+func (ai *AdvancedAIBrain) SetShowStrategy(s ShowStrategy) { ai.showStrategy = s }
@@ -0,0 +1,319 @@
+// solver.go
+// An exhaustive deduction engine. Rather than applying inference rules it
+// tries every possible solution and keeps those for which some deal of the
+// remaining cards fits everything a player has observed.
+
+package main
+
+import (
+	"maps"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Observation is everything one player knows about the other hands.
+type Observation struct {
+	players    []string // Seating order.
+	handSize   map[string]int
+	holds      map[string]map[string]bool
+	lacks      map[string]map[string]bool
+	holdsOneOf []oneOfConstraint
+}
+
+// oneOfConstraint records that a player holds at least one of some cards.
+type oneOfConstraint struct {
+	player string
+	cards  []string
+}
+
+// NewObservation starts from what a seated player knows before the first
+// turn: their own hand and how many cards everyone holds.
+func NewObservation(cfg GameConfig, players []string, handSize map[string]int, me string, hand []string) *Observation {
+	o := &Observation{
+		players:  players,
+		handSize: handSize,
+		holds:    make(map[string]map[string]bool),
+		lacks:    make(map[string]map[string]bool),
+	}
+	for _, p := range players {
+		o.holds[p] = make(map[string]bool)
+		o.lacks[p] = make(map[string]bool)
+	}
+	mine := make(map[string]bool)
+	for _, card := range hand {
+		mine[card] = true
+	}
+	for _, card := range cfg.AllCards {
+		if mine[card] {
+			o.holds[me][card] = true
+		} else {
+			o.lacks[me][card] = true
+		}
+	}
+	return o
+}
+
+// ApplyTurn adds what the observing player learns from a turn: the players
+// who passed hold none of the cards, and the disprover holds the shown card
+// if the observer saw it, or at least one of the suggested cards otherwise.
+func (o *Observation) ApplyTurn(t TurnRecord, me string) {
+	cards := values(t.Suggestion)
+	start := -1
+	for i, p := range o.players {
+		if p == t.Suggester {
+			start = i
+		}
+	}
+	if start == -1 {
+		return
+	}
+	for i := 1; i < len(o.players); i++ {
+		p := o.players[(start+i)%len(o.players)]
+		if p == t.Disprover {
+			break
+		}
+		for _, card := range cards {
+			o.lacks[p][card] = true
+		}
+	}
+	if t.Disprover == "" {
+		return
+	}
+	if t.Shown != "" && (me == t.Suggester || me == t.Disprover) {
+		o.holds[t.Disprover][t.Shown] = true
+		return
+	}
+	o.holdsOneOf = append(o.holdsOneOf, oneOfConstraint{player: t.Disprover, cards: cards})
+}
+
+// PossibleSolutions returns the solutions consistent with the observation,
+// stopping once limit have been found (limit <= 0 means no limit).
+func (o *Observation) PossibleSolutions(cfg GameConfig, limit int) []map[string]string {
+	var found []map[string]string
+	solution := make(map[string]string)
+	var try func(i int) bool // Reports whether the limit was reached.
+	try = func(i int) bool {
+		if i == len(cfg.Categories) {
+			if o.feasible(cfg, solution) {
+				found = append(found, maps.Clone(solution))
+			}
+			return limit > 0 && len(found) >= limit
+		}
+		cat := cfg.Categories[i]
+		for _, card := range cat.Cards {
+			solution[cat.Name] = card
+			if try(i + 1) {
+				return true
+			}
+		}
+		return false
+	}
+	try(0)
+	return found
+}
+
+// dealSearch is the backtracking state for one candidate solution.
+type dealSearch struct {
+	obs       *Observation
+	free      []string            // Cards still to place, most constrained first.
+	allowed   map[string][]string // Players each free card may go to.
+	owner     map[string]string
+	remaining map[string]int
+}
+
+// feasible reports whether the remaining cards can be dealt so that every
+// observation holds, given that the solution is the one proposed.
+func (o *Observation) feasible(cfg GameConfig, solution map[string]string) bool {
+	inSolution := make(map[string]bool)
+	for _, card := range solution {
+		inSolution[card] = true
+	}
+	s := &dealSearch{obs: o, allowed: make(map[string][]string), owner: make(map[string]string), remaining: make(map[string]int)}
+	for p, n := range o.handSize {
+		s.remaining[p] = n
+	}
+	for _, card := range cfg.AllCards {
+		holder := ""
+		for _, p := range o.players {
+			if o.holds[p][card] {
+				holder = p
+			}
+		}
+		if inSolution[card] {
+			if holder != "" {
+				return false
+			}
+			continue
+		}
+		if holder != "" {
+			s.owner[card] = holder
+			s.remaining[holder]--
+			if s.remaining[holder] < 0 {
+				return false
+			}
+			continue
+		}
+		for _, p := range o.players {
+			if !o.lacks[p][card] {
+				s.allowed[card] = append(s.allowed[card], p)
+			}
+		}
+		if len(s.allowed[card]) == 0 {
+			return false
+		}
+		s.free = append(s.free, card)
+	}
+	total := 0
+	for _, n := range s.remaining {
+		total += n
+	}
+	if total != len(s.free) {
+		return false
+	}
+	sort.SliceStable(s.free, func(i, j int) bool { return len(s.allowed[s.free[i]]) < len(s.allowed[s.free[j]]) })
+	return s.search(0)
+}
+
+func (s *dealSearch) search(i int) bool {
+	if !s.viable(i) {
+		return false
+	}
+	if i == len(s.free) {
+		return true
+	}
+	card := s.free[i]
+	for _, p := range s.allowed[card] {
+		if s.remaining[p] == 0 {
+			continue
+		}
+		s.owner[card] = p
+		s.remaining[p]--
+		if s.search(i + 1) {
+			delete(s.owner, card)
+			s.remaining[p]++
+			return true
+		}
+		delete(s.owner, card)
+		s.remaining[p]++
+	}
+	return false
+}
+
+// viable prunes partial deals: every player must still be able to fill their
+// hand, and every "holds one of" constraint must still be satisfiable.
+func (s *dealSearch) viable(next int) bool {
+	open := make(map[string]int)
+	for _, card := range s.free[next:] {
+		for _, p := range s.allowed[card] {
+			open[p]++
+		}
+	}
+	for p, n := range s.remaining {
+		if open[p] < n {
+			return false
+		}
+	}
+	for _, c := range s.obs.holdsOneOf {
+		ok := false
+		for _, card := range c.cards {
+			owner, placed := s.owner[card]
+			if placed && owner == c.player {
+				ok = true
+				break
+			}
+			if !placed && s.remaining[c.player] > 0 {
+				for _, p := range s.allowed[card] {
+					if p == c.player {
+						ok = true
+						break
+					}
+				}
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// EarliestSolveTurn replays a scenario's log from one player's seat and
+// returns the first turn after which a perfect deducer in that seat would
+// know the solution, or -1 if the log never pins it down.
+func EarliestSolveTurn(sc *Scenario, cfg GameConfig, player string) int {
+	handSize := make(map[string]int)
+	for _, p := range sc.Players {
+		handSize[p] = len(sc.Hands[p])
+	}
+	obs := NewObservation(cfg, sc.Players, handSize, player, sc.Hands[player])
+	if len(obs.PossibleSolutions(cfg, 2)) == 1 {
+		return 0
+	}
+	for i, t := range sc.Log {
+		obs.ApplyTurn(t, player)
+		if len(obs.PossibleSolutions(cfg, 2)) == 1 {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func runEarliestSolve(path string) {
+	sc, err := LoadScenario(path, config)
+	if err != nil {
+		C.Warn.Printf("Could not load scenario: %v\n", err)
+		return
+	}
+	C.Header.Printf("--- Earliest possible solve (of %d logged turns) ---\n", len(sc.Log))
+	for _, p := range sc.Players {
+		if turn := EarliestSolveTurn(sc, config, p); turn >= 0 {
+			C.Info.Printf("%-16s could have known the solution after turn %d.\n", p, turn)
+		} else {
+			C.Info.Printf("%-16s could not have known the solution.\n", p)
+		}
+	}
+	if sc.Winner != "" {
+		C.Info.Printf("%s actually accused on turn %d.\n", colorizeCard(sc.Winner), sc.AccusationTurn)
+	}
+}
+
+// runExportScenario plays an all-AI game and saves the full deal and log.
+func runExportScenario(path string, numAI int) error {
+	g, err := NewGame(config, 0, numAI)
+	if err != nil {
+		return err
+	}
+	if log.GetLevel() > logrus.WarnLevel {
+		log.SetLevel(logrus.WarnLevel)
+	}
+	if err := g.Deal(); err != nil {
+		return err
+	}
+	sc := Scenario{Solution: g.Solution, Hands: g.Hands}
+	for _, p := range g.Players {
+		sc.Players = append(sc.Players, p.Name())
+	}
+	for g.turn < defaultTurnLimit {
+		out := g.PlayTurn()
+		if g.Finished() {
+			sc.Winner, sc.AccusationTurn = g.Winner(), turnsPlayed(g, out)
+			break
+		}
+		if out.Suggestion != nil {
+			sc.Log = append(sc.Log, TurnRecord{Suggester: out.Player.Name(), Suggestion: out.Suggestion, Disprover: out.Disprover, Shown: out.RevealedCard})
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := sc.Write(f); err != nil {
+		return err
+	}
+	C.Info.Printf("Wrote a %d-turn scenario (%s) to %s.\n", len(sc.Log), strings.Join(sc.Players, ", "), path)
+	return nil
+}
@@ -0,0 +1,81 @@
+// soundness.go
+// A fully known deal, to play suggestions against and to check a brain's
+// notes with: brains fed legal turns must never write down a fact that
+// contradicts it.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TestableGame is a fully known deal: the solution and every hand. It can
+// answer suggestions the way the table would and check a brain's notes
+// against the truth.
+type TestableGame struct {
+	Players  []string // In seating order.
+	Solution map[string]string
+	Hands    map[string][]string
+	owner    map[string]string // Card to player, or "solution".
+	config   GameConfig        // The deck that was dealt.
+}
+
+// NewTestableGame captures the ground truth of a dealt game.
+func NewTestableGame(g *Game) *TestableGame {
+	tg := &TestableGame{Solution: g.Solution, Hands: g.Hands, owner: make(map[string]string), config: g.Config}
+	for _, p := range g.Players {
+		tg.Players = append(tg.Players, p.Name())
+		for _, card := range g.Hands[p.Name()] {
+			tg.owner[card] = p.Name()
+		}
+	}
+	for _, card := range g.Solution {
+		tg.owner[card] = "solution"
+	}
+	return tg
+}
+
+// Owner returns the player holding card, or "solution".
+func (tg *TestableGame) Owner(card string) string { return tg.owner[card] }
+
+// Disprove answers a suggestion legally: the first player after the
+// suggester holding a suggested card shows one of them, chosen by rng.
+func (tg *TestableGame) Disprove(suggester string, suggestion map[string]string, rng Rng) (disprover, shown string) {
+	seat := 0
+	for i, p := range tg.Players {
+		if p == suggester {
+			seat = i
+		}
+	}
+	for i := 1; i < len(tg.Players); i++ {
+		p := tg.Players[(seat+i)%len(tg.Players)]
+		var matches []string
+		for _, cat := range tg.config.CategoryNames() {
+			if tg.owner[suggestion[cat]] == p {
+				matches = append(matches, suggestion[cat])
+			}
+		}
+		if len(matches) > 0 {
+			return p, matches[rng.Intn(len(matches))]
+		}
+	}
+	return "", ""
+}
+
+// Check reports every cell of the brain's notes that contradicts the deal,
+// naming the rule behind each false placement.
+func (tg *TestableGame) Check(ai *AdvancedAIBrain) error {
+	var errs []error
+	for _, d := range ai.Reasoning() {
+		if tg.owner[d.Card] != d.Location {
+			errs = append(errs, fmt.Errorf("%s placed %s with %s by %s after entry %d, but %s has it", ai.Name(), d.Card, d.Location, d.Reason, d.Entry, tg.owner[d.Card]))
+		}
+	}
+	for card, row := range ai.knowledge {
+		if row[tg.owner[card]] == StatusNo {
+			errs = append(errs, fmt.Errorf("%s rules %s out of %s, which has it", ai.Name(), card, tg.owner[card]))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"testing"
+)
+
+// checkDeductions deals a seeded game, feeds every brain turns of random
+// legal suggestions and checks all notes after each turn. It returns the
+// first contradiction found, if any.
+func checkDeductions(numPlayers, turns int, seed int64) error {
+	rng := rand.New(rand.NewSource(seed))
+	g, err := NewGameWithRng(config, 0, numPlayers, rng)
+	if err != nil {
+		return err
+	}
+	if err := g.Deal(); err != nil {
+		return err
+	}
+	tg := NewTestableGame(g)
+	for turn := 1; turn <= turns; turn++ {
+		suggester := tg.Players[turn%numPlayers]
+		suggestion := make(map[string]string)
+		for _, cat := range config.Categories {
+			suggestion[cat.Name] = cat.Cards[rng.Intn(len(cat.Cards))]
+		}
+		disprover, shown := tg.Disprove(suggester, suggestion, rng)
+		for _, p := range g.Players {
+			revealed := ""
+			if p.Name() == suggester {
+				revealed = shown
+			}
+			p.ProcessTurnInfo(suggester, disprover, revealed, suggestion)
+		}
+		for _, p := range g.Players {
+			if err := tg.Check(p.(*AdvancedAIBrain)); err != nil {
+				return fmt.Errorf("turn %d: %w", turn, err)
+			}
+		}
+	}
+	return nil
+}
+
+func TestDeductionsNeverContradictDeal(t *testing.T) {
+	const gamesPerSize, turnsPerGame = 25, 60
+	for _, numPlayers := range []int{2, 3, 4, 6} {
+		t.Run(fmt.Sprintf("%d players", numPlayers), func(t *testing.T) {
+			for seed := int64(1); seed <= gamesPerSize; seed++ {
+				if err := checkDeductions(numPlayers, turnsPerGame, seed); err != nil {
+					t.Fatalf("seed %d: %v", seed, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDisproveUsesTheDealtDeck(t *testing.T) {
+	// A fourth category the shared config does not have.
+	cfg := GameConfig{
+		Suspects: config.Suspects, Weapons: config.Weapons, Rooms: config.Rooms,
+		Extra: []Category{{Name: "motives", Cards: []string{"Greed", "Revenge", "Jealousy"}}},
+	}
+	cfg.buildIndex()
+	g, err := NewGameWithRng(cfg, 0, 3, rand.New(rand.NewSource(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Deal(); err != nil {
+		t.Fatal(err)
+	}
+	tg := NewTestableGame(g)
+
+	var holder, motive string
+	for _, card := range cfg.CardsOf("motives") {
+		if owner := tg.Owner(card); owner != "solution" {
+			holder, motive = owner, card
+		}
+	}
+	suggestion := maps.Clone(g.Solution)
+	suggestion["motives"] = motive
+	suggester := tg.Players[0]
+	if suggester == holder {
+		suggester = tg.Players[1]
+	}
+	// Only the motive can be shown, so only a Disprove that knows the
+	// category finds it.
+	if disprover, shown := tg.Disprove(suggester, suggestion, rand.New(rand.NewSource(1))); disprover != holder || shown != motive {
+		t.Errorf("Disprove = %s, %s; want %s to show %s", disprover, shown, holder, motive)
+	}
+}
@@ -0,0 +1,127 @@
+// stats.go
+// Aggregate results over many games, for win-rate analysis.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/sirupsen/logrus"
+)
+
+// StatsCollector tallies results across games. Subscribe it to every game as
+// a TurnListener and call GameOver when each one ends. It is safe to share
+// between games played in parallel.
+type StatsCollector struct {
+	mu          sync.Mutex
+	games       int
+	wins        map[string]int
+	winTurns    int
+	accusations int
+	correct     int
+}
+
+// StatsReport is a snapshot of a StatsCollector.
+type StatsReport struct {
+	Games              int
+	Wins               map[string]int // By player name.
+	AverageTurnsToWin  float64        // Over games that were won.
+	Accusations        int
+	CorrectAccusations int
+	AccuracyRate       float64 // CorrectAccusations / Accusations, or 0.
+}
+
+func NewStatsCollector() *StatsCollector {
+	return &StatsCollector{wins: make(map[string]int)}
+}
+
+// Turn records accusations.
+func (s *StatsCollector) Turn(turn int, out TurnOutcome) {
+	if out.Accusation == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accusations++
+	if out.Correct {
+		s.correct++
+	}
+}
+
+// GameOver counts a finished or abandoned game, and its winner, if any. A
+// game can be won by accusing correctly or by outlasting everyone else.
+func (s *StatsCollector) GameOver(g *Game) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.games++
+	if winner := g.Winner(); winner != "" {
+		s.wins[winner]++
+		s.winTurns += len(g.History())
+	}
+}
+
+// Report returns the aggregates so far.
+func (s *StatsCollector) Report() StatsReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := StatsReport{Games: s.games, Wins: make(map[string]int), Accusations: s.accusations, CorrectAccusations: s.correct}
+	won := 0
+	for name, n := range s.wins {
+		r.Wins[name] = n
+		won += n
+	}
+	if won > 0 {
+		r.AverageTurnsToWin = float64(s.winTurns) / float64(won)
+	}
+	if s.accusations > 0 {
+		r.AccuracyRate = float64(s.correct) / float64(s.accusations)
+	}
+	return r
+}
+
+func runBench(numAI, numGames, turnLimit int, seed int64) {
+	if config.CheckPlayers(numAI) != nil || numGames < 1 {
+		C.Warn.Printf("Usage: bench <num_ai %d-%d> <num_games>\n", minPlayers, config.MaxPlayers())
+		return
+	}
+	if log.GetLevel() > logrus.WarnLevel {
+		log.SetLevel(logrus.WarnLevel)
+	}
+
+	C.Header.Printf("--- Collecting statistics over %d games with %d AIs ---\n", numGames, numAI)
+	stats := NewStatsCollector()
+	if _, err := runBatch(config, numAI, batchSeeds(seed, numGames), turnLimit, stats, newProgressBar(os.Stderr).Update); err != nil {
+		C.Warn.Printf("Bench aborted: %v\n", err)
+		return
+	}
+	r := stats.Report()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendRows([]table.Row{
+		{"Games", r.Games},
+		{"Average turns to win", fmt.Sprintf("%.1f", r.AverageTurnsToWin)},
+		{"Accusations", r.Accusations},
+		{"Accusation accuracy", fmt.Sprintf("%.1f%%", r.AccuracyRate*100)},
+	})
+	var names []string
+	for name := range r.Wins {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if r.Wins[names[i]] != r.Wins[names[j]] {
+			return r.Wins[names[i]] > r.Wins[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	t.AppendSeparator()
+	for _, name := range names {
+		t.AppendRow(table.Row{"Wins: " + name, fmt.Sprintf("%d (%.1f%%)", r.Wins[name], float64(r.Wins[name])*100/float64(r.Games))})
+	}
+	t.SetStyle(tableStyle(table.StyleLight))
+	t.Render()
+}
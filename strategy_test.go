@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bytes"
+	"maps"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// meanTurns plays a seeded batch in which every brain uses only the given
+// strategies and returns the average number of turns a game lasted.
+func meanTurns(t *testing.T, numAI int, seeds []int64, strategies ...string) float64 {
+	t.Helper()
+	cfg := config
+	cfg.AI.Strategies = strategies
+	results, err := RunBatch(cfg, numAI, seeds)
+	if err != nil {
+		t.Fatalf("RunBatch: %v", err)
+	}
+	total := 0
+	for _, r := range results {
+		total += r.Turns
+	}
+	return float64(total) / float64(len(results))
+}
+
+func TestInfoGainOutperformsExplore(t *testing.T) {
+	seeds := make([]int64, 100)
+	for i := range seeds {
+		seeds[i] = int64(i + 1)
+	}
+	for _, numAI := range []int{3, 4} {
+		explore := meanTurns(t, numAI, seeds, StrategyExplore)
+		infoGain := meanTurns(t, numAI, seeds, StrategyInfoGain)
+		if infoGain >= explore {
+			t.Errorf("%d players: Information Gain took %.2f turns on average, Explore %.2f", numAI, infoGain, explore)
+		}
+	}
+}
+
+// firstRng always draws the first option and never shuffles.
+type firstRng struct{}
+
+func (firstRng) Intn(int) int                { return 0 }
+func (firstRng) Shuffle(int, func(i, j int)) {}
+
+// mysteryBrain returns a brain whose notes hold three open mysteries that
+// all share one suspect, the most frequent Surgical Strike target.
+func mysteryBrain(patience int) (*AdvancedAIBrain, string) {
+	suspects, weapons, rooms := config.CardsOf("suspects"), config.CardsOf("weapons"), config.CardsOf("rooms")
+	ai := NewAdvancedAIBrain().WithPatience(patience)
+	ai.SetRng(firstRng{})
+	ai.Setup(config, []string{"Me", "Left", "Right"}, "Me")
+	top := suspects[1]
+	for i := range 3 {
+		ai.unresolvedSuggestions = append(ai.unresolvedSuggestions, UnresolvedSuggestion{
+			Disprover:     "Left",
+			PossibleCards: map[string]struct{}{top: {}, weapons[i]: {}, rooms[i]: {}},
+		})
+	}
+	return ai, top
+}
+
+func TestZeroPatienceAlwaysRetargetsMostFrequentCard(t *testing.T) {
+	ai, top := mysteryBrain(0)
+	for turn := range 5 {
+		suggestion, ok := ai._buildSurgicalStrike()
+		if !ok {
+			t.Fatalf("turn %d: no Surgical Strike", turn)
+		}
+		if suggestion["suspects"] != top {
+			t.Fatalf("turn %d: suggested %v, want it to target %s", turn, suggestion, top)
+		}
+	}
+}
+
+func TestPatienceAvoidsRecentTarget(t *testing.T) {
+	ai, top := mysteryBrain(1)
+	if suggestion, _ := ai._buildSurgicalStrike(); suggestion["suspects"] != top {
+		t.Fatalf("first strike suggested %v, want it to target %s", suggestion, top)
+	}
+	if suggestion, _ := ai._buildSurgicalStrike(); suggestion["suspects"] == top {
+		t.Errorf("second strike suggested %v, want it to avoid %s", suggestion, top)
+	}
+}
+
+func TestCertainBrainAccusesWhateverThreshold(t *testing.T) {
+	want := map[string]string{}
+	for _, cat := range config.CategoryNames() {
+		want[cat] = config.CardsOf(cat)[0]
+	}
+	for _, threshold := range []float64{0, 0.3, 0.5, 0.9, 0.99, 1, 2} {
+		for _, turnsSeen := range []int{0, 200} {
+			ai := NewAdvancedAIBrain().WithAccusationThreshold(threshold)
+			ai.Setup(config, []string{"Me", "Left", "Right"}, "Me")
+			for _, card := range want {
+				ai._markCardLocation(card, "solution", RuleUndisproved)
+			}
+			ai._runDeductionLoop()
+			ai.turnsSeen = turnsSeen
+			if got := ai.ShouldAccuse(); !maps.Equal(got, want) {
+				t.Errorf("threshold %v after %d turns: ShouldAccuse = %v, want %v", threshold, turnsSeen, got, want)
+			}
+		}
+	}
+}
+
+func TestContradictionIsWarnedAboutOnce(t *testing.T) {
+	savedOut := log.Out
+	t.Cleanup(func() { log.SetOutput(savedOut) })
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+
+	ai := NewAdvancedAIBrain()
+	ai.Setup(config, []string{"Me", "Left", "Right"}, "Me")
+	first, second := config.AllCards[0], config.AllCards[1]
+	ai.knowledge[first]["Left"] = StatusYes
+	ai.knowledge[first]["Right"] = StatusYes
+	warnings := func() int { return strings.Count(logs.String(), "contradict themselves") }
+
+	for range 3 {
+		if got := ai.ShouldAccuse(); got != nil {
+			t.Fatalf("ShouldAccuse = %v with contradictory notes", got)
+		}
+	}
+	if n := warnings(); n != 1 {
+		t.Errorf("one contradiction over three turns logged %d warnings, want 1", n)
+	}
+	ai.knowledge[second]["Left"] = StatusYes
+	ai.knowledge[second]["solution"] = StatusYes
+	ai.ShouldAccuse()
+	ai.ShouldAccuse()
+	if n := warnings(); n != 2 {
+		t.Errorf("a second contradiction brought the total to %d warnings, want 2", n)
+	}
+}
+
+func TestPartialSuggestionIsCorrected(t *testing.T) {
+	ai := NewAdvancedAIBrain()
+	ai.Setup(config, []string{"Me", "Left", "Right"}, "Me")
+	for _, partial := range []map[string]string{
+		nil,
+		{"suspects": config.CardsOf("suspects")[0]},
+		{"suspects": config.CardsOf("suspects")[0], "weapons": config.CardsOf("weapons")[0]},
+		{"suspects": config.CardsOf("suspects")[0], "weapons": "", "rooms": config.CardsOf("rooms")[0]},
+	} {
+		if got := ai._legalSuggestion(partial); !isCompleteSuggestion(config, got) {
+			t.Errorf("%v was corrected to %v, want a full suggestion", partial, got)
+		}
+	}
+}
+
+func TestExploreEnumerationIsDistinct(t *testing.T) {
+	ai := threePlayerBrain()
+	ai.ReceiveHand([]string{config.CardsOf("suspects")[0], config.CardsOf("weapons")[0], config.CardsOf("rooms")[0]})
+	suggestions := ai._enumerateExplorationSuggestions()
+	if len(suggestions) == 0 {
+		t.Fatal("no exploration suggestions")
+	}
+	seen := make(map[string]bool)
+	for _, s := range suggestions {
+		if !isCompleteSuggestion(config, s) {
+			t.Fatalf("incomplete suggestion %v", s)
+		}
+		key := s["suspects"] + "|" + s["weapons"] + "|" + s["rooms"]
+		if seen[key] {
+			t.Fatalf("%v is enumerated twice", s)
+		}
+		seen[key] = true
+		for _, card := range s {
+			if _, mine := ai.hand[card]; mine {
+				t.Fatalf("%v names our own %s", s, card)
+			}
+		}
+	}
+}
+
+func TestBanditFavorsTheProductiveStrategy(t *testing.T) {
+	ai := threePlayerBrain()
+	applicable := []string{StrategyExploit, StrategySurgical, StrategyExplore}
+	// Surgical Strike always teaches four facts; the others teach 0, 1 or 2
+	// in turn.
+	const rounds = 300
+	pulls := make(map[string]int)
+	for i := range rounds {
+		ai.lastStrategy = ai._selectStrategy(applicable)
+		pulls[ai.lastStrategy]++
+		reward := i % 3
+		if ai.lastStrategy == StrategySurgical {
+			reward = 4
+		}
+		ai._rewardStrategy(reward)
+	}
+	if pulls[StrategySurgical] < rounds*8/10 {
+		t.Errorf("pulls = %v, want Surgical Strike chosen at least 80%% of the time", pulls)
+	}
+	for _, name := range applicable {
+		if pulls[name] == 0 {
+			t.Errorf("%s was never tried", name)
+		}
+	}
+}
+
+func TestFocusProbesTheCategoryWithTwoCandidates(t *testing.T) {
+	suspects, weapons, rooms := config.CardsOf("suspects"), config.CardsOf("weapons"), config.CardsOf("rooms")
+	for seed := int64(1); seed <= 10; seed++ {
+		ai := threePlayerBrain().WithStrategies(StrategyFocus)
+		ai.SetRng(rand.New(rand.NewSource(seed)))
+		// Only the first two weapons can be the solution; every other
+		// category is wide open.
+		ai.ReceiveHand([]string{weapons[2], weapons[3], weapons[4], weapons[5], suspects[5], rooms[8]})
+		if got := ai._focusCategory(); got != "weapons" {
+			t.Fatalf("focus category = %q, want weapons", got)
+		}
+		suggestion := ai.MakeSuggestion()
+		if ai.lastStrategy != StrategyFocus {
+			t.Errorf("seed %d: used %s, want %s", seed, ai.lastStrategy, StrategyFocus)
+		}
+		if w := suggestion["weapons"]; w != weapons[0] && w != weapons[1] {
+			t.Errorf("seed %d: suggested the %s, want one of the two candidate weapons", seed, w)
+		}
+	}
+}
+
+func TestAIOptionsStayWithTheirConfig(t *testing.T) {
+	patience := 0
+	cfg := config
+	cfg.AI = AIOptions{
+		Strategies: []string{StrategyFocus, StrategyExplore},
+		ExploitMRV: true,
+		Patience:   &patience,
+		Accusation: NewAccusationPolicy(0.9),
+		Show:       MinimalInfoShowStrategy{},
+	}
+	g, err := NewGameWithRng(cfg, 0, 3, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range g.Players {
+		ai := p.(*AdvancedAIBrain)
+		if !slices.Equal(ai.strategies, cfg.AI.Strategies) || !ai.exploitMRV {
+			t.Errorf("%s uses %v with MRV %t, want %v with MRV", ai.Name(), ai.strategies, ai.exploitMRV, cfg.AI.Strategies)
+		}
+		if ai.patience != 0 || ai.accusation != cfg.AI.Accusation || ai.showStrategy != cfg.AI.Show {
+			t.Errorf("%s has patience %d, policy %+v and show strategy %T", ai.Name(), ai.patience, ai.accusation, ai.showStrategy)
+		}
+	}
+
+	// Games on the shared config still get the standard brain.
+	g = newSeededGame(t, 3, 1)
+	for _, p := range g.Players {
+		ai := p.(*AdvancedAIBrain)
+		if ai.strategies != nil || ai.exploitMRV || ai.patience != DefaultPatience || ai.accusation != (AccusationPolicy{}) || ai.showStrategy != nil {
+			t.Errorf("%s on the shared config is not a standard brain", ai.Name())
+		}
+	}
+}
+
+func TestWeightedChooser(t *testing.T) {
+	cards := []string{"a", "b", "c"}
+	score := func(card string) float64 { return map[string]float64{"a": 1, "b": 3, "c": -2}[card] }
+	if got := (WeightedChooser{Score: score, Argmax: true}).Choose(cards); got != "b" {
+		t.Errorf("argmax chose %q, want b", got)
+	}
+	counts := make(map[string]int)
+	chooser := WeightedChooser{Rng: rand.New(rand.NewSource(1)), Score: score}
+	for range 4000 {
+		counts[chooser.Choose(cards)]++
+	}
+	// Weights 1:3:0, with the negative score counted as zero.
+	if counts["c"] != 0 || counts["b"] < 2*counts["a"] || counts["a"] == 0 {
+		t.Errorf("draws %v, want about 1000 a, 3000 b and no c", counts)
+	}
+}
+
+func TestExploitMRVProbesTheCategoryClosestToSolved(t *testing.T) {
+	suspects, weapons := config.CardsOf("suspects"), config.CardsOf("weapons")
+	for seed := int64(1); seed <= 5; seed++ {
+		ai := threePlayerBrain().WithStrategies(StrategyExploit).WithExploitMRV(true)
+		ai.SetRng(rand.New(rand.NewSource(seed)))
+		// The murderer is known, and only the first two weapons can still
+		// be the solution; every room is open.
+		ai.ReceiveHand(append(slices.Clone(suspects[1:]), weapons[2]))
+		for _, w := range weapons[3:] {
+			ai.knowledge[w]["solution"] = StatusNo
+		}
+		// Both candidates are equally open, so the tie goes to the
+		// alphabetically first.
+		if got := ai.MakeSuggestion()["weapons"]; got != weapons[0] {
+			t.Errorf("seed %d: Exploit with MRV named the %s, want the %s", seed, got, weapons[0])
+		}
+	}
+	if NewAdvancedAIBrain().exploitMRV {
+		t.Error("a new brain uses MRV without being asked to")
+	}
+}
@@ -0,0 +1,75 @@
+// svg.go
+// Renders the notes grid as a standalone SVG image for web pages and reports.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"os"
+)
+
+// Layout of the SVG grid, in pixels.
+const (
+	svgCardWidth = 160
+	svgCellWidth = 90
+	svgRowHeight = 24
+	svgTitleH    = 32
+)
+
+var svgStatusFill = map[CardStatus]string{
+	StatusYes:   "#8bc34a",
+	StatusNo:    "#e57373",
+	StatusMaybe: "#eeeeee",
+}
+
+var svgStatusGlyph = map[CardStatus]string{
+	StatusYes:   "✔",
+	StatusNo:    "✖",
+	StatusMaybe: "?",
+}
+
+// ExportNotesSVG writes the brain's notes grid as an SVG table: a header row
+// of locations, then one row per card with a colored cell per location.
+func ExportNotesSVG(w io.Writer, ai *AdvancedAIBrain) error {
+	locations := append(append([]string{}, ai.players...), "solution")
+	width := svgCardWidth + svgCellWidth*len(locations)
+	height := svgTitleH + svgRowHeight*(len(ai.config.AllCards)+1)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" font-family=\"sans-serif\" font-size=\"12\">\n", width, height)
+	fmt.Fprintf(bw, "<text x=\"%d\" y=\"20\" text-anchor=\"middle\" font-size=\"16\" font-weight=\"bold\">%s's Detective Notes</text>\n", width/2, html.EscapeString(ai.name))
+
+	y := svgTitleH
+	for i, loc := range locations {
+		x := svgCardWidth + i*svgCellWidth
+		fmt.Fprintf(bw, "<text x=\"%d\" y=\"%d\" text-anchor=\"middle\" font-weight=\"bold\">%s</text>\n", x+svgCellWidth/2, y+16, html.EscapeString(loc))
+	}
+	for _, card := range ai.config.AllCards {
+		y += svgRowHeight
+		fmt.Fprintf(bw, "<text x=\"4\" y=\"%d\">%s</text>\n", y+16, html.EscapeString(card))
+		for i, loc := range locations {
+			status := ai.knowledge[card][loc]
+			x := svgCardWidth + i*svgCellWidth
+			fmt.Fprintf(bw, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\" stroke=\"#ffffff\"/>\n", x, y, svgCellWidth, svgRowHeight, svgStatusFill[status])
+			fmt.Fprintf(bw, "<text x=\"%d\" y=\"%d\" text-anchor=\"middle\">%s</text>\n", x+svgCellWidth/2, y+16, svgStatusGlyph[status])
+		}
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// writeNotesSVG saves a brain's notes grid to an SVG file.
+func writeNotesSVG(path string, ai *AdvancedAIBrain) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := ExportNotesSVG(f, ai); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
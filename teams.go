@@ -0,0 +1,92 @@
+// teams.go
+// House-rule partnerships: teammates see the cards shown to one another and
+// win together.
+
+package main
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// SetTeams puts players into partnerships, keyed by team number; players
+// left out play alone. Every card shown to a player is also shown to their
+// teammates, and a correct accusation wins for the whole team. Call it after
+// SetSeating, which reseats everyone, and before play starts.
+func (g *Game) SetTeams(teams map[string]int) error {
+	for name := range teams {
+		if g.playerIndex(name) < 0 {
+			return fmt.Errorf("%q is not playing", name)
+		}
+	}
+	g.teams = maps.Clone(teams)
+	return nil
+}
+
+// playerIndex returns the seat of the named player, or -1.
+func (g *Game) playerIndex(name string) int {
+	for i, p := range g.Players {
+		if p.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameTeam reports whether two different players are partners.
+func (g *Game) sameTeam(a, b string) bool {
+	ta, okA := g.teams[a]
+	tb, okB := g.teams[b]
+	return a != b && okA && okB && ta == tb
+}
+
+// Teammates returns the named player's partners in seating order.
+func (g *Game) Teammates(name string) []string {
+	var mates []string
+	for _, p := range g.Players {
+		if g.sameTeam(name, p.Name()) {
+			mates = append(mates, p.Name())
+		}
+	}
+	return mates
+}
+
+// WinningTeam returns the winner and their teammates, or nil while the game
+// is undecided.
+func (g *Game) WinningTeam() []string {
+	if g.winner == "" {
+		return nil
+	}
+	return append([]string{g.winner}, g.Teammates(g.winner)...)
+}
+
+// oneSideLeft reports whether every player still in the game is the current
+// player or one of their teammates.
+func (g *Game) oneSideLeft() bool {
+	current := g.CurrentPlayer().Name()
+	for _, p := range g.Players {
+		name := p.Name()
+		if !g.eliminated[name] && name != current && !g.sameTeam(name, current) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTeams reads partnerships written as groups joined by '+' and
+// separated by commas, e.g. "1+2,3+4" or "Miss Scarlett+Mrs. White".
+// Suspects may be given by name or by number.
+func parseTeams(spec string) map[string]int {
+	teams := make(map[string]int)
+	for i, group := range strings.Split(spec, ",") {
+		for _, name := range strings.Split(group, "+") {
+			card := lookupCard(name)
+			if config.CardToType[card] != "suspects" {
+				card = strings.TrimSpace(name) // Let SetTeams report it.
+			}
+			teams[card] = i + 1
+		}
+	}
+	return teams
+}